@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"golang-rest/internal/adapters/outbound/file_repository"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/config"
+)
+
+// newAuditRepository keeps the audit log in the storage backend (AUDIT_SINK=store, the default) or
+// appends it to AUDIT_FILE (AUDIT_SINK=file). The SQL backend has no audit store and always uses the file.
+func newAuditRepository(store ports.AuditRepositoryInterface) (ports.AuditRepositoryInterface, error) {
+	switch sink := config.GetEnv("AUDIT_SINK", "store"); sink {
+	case "store":
+		if store != nil {
+			return store, nil
+		}
+		fallthrough
+	case "file":
+		return file_repository.NewAuditRepository(config.GetEnv("AUDIT_FILE", "audit.log")), nil
+	default:
+		return nil, fmt.Errorf("unknown AUDIT_SINK %q", sink)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"golang-rest/internal/infrastructure/config"
+	"golang-rest/internal/infrastructure/jwtkeys"
+	"log"
+	"os"
+)
+
+// newKeyManager signs with the PEM key in JWT_SIGNING_KEY_FILE and still accepts tokens from the keys
+// in JWT_VERIFY_KEY_FILES, which is where a rotated out key goes until its last token has expired.
+// Without a key file it makes up a JWT_ALGORITHM key, so tokens do not survive a restart.
+func newKeyManager() (*jwtkeys.KeyManager, error) {
+	var signing jwtkeys.Key
+	var err error
+	if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+		signing, err = readKeyFile(path)
+	} else {
+		log.Println("JWT_SIGNING_KEY_FILE is not set, using a temporary signing key")
+		signing, err = jwtkeys.GenerateKey(config.GetEnv("JWT_ALGORITHM", jwtkeys.EdDSA))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var verifyOnly []jwtkeys.Key
+	for _, path := range config.GetList("JWT_VERIFY_KEY_FILES") {
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verifyOnly = append(verifyOnly, key)
+	}
+	return jwtkeys.NewKeyManager(signing, verifyOnly...)
+}
+
+func readKeyFile(path string) (jwtkeys.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return jwtkeys.Key{}, err
+	}
+	key, err := jwtkeys.ParsePEM(data)
+	if err != nil {
+		return jwtkeys.Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
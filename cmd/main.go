@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	grpcadapter "golang-rest/internal/adapters/inbound/grpc"
 	"golang-rest/internal/adapters/inbound/http"
-	"golang-rest/internal/adapters/outbound/mongo_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/audit"
 	"golang-rest/internal/infrastructure/background"
+	"golang-rest/internal/infrastructure/config"
 	"golang-rest/internal/infrastructure/middleware"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -30,28 +37,122 @@ func main() {
 	var wg sync.WaitGroup
 
 	// Initialize a new Fiber app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ProxyHeader: os.Getenv("PROXY_HEADER")})
 
-	// Connect to MongoDB
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_URI")))
+	// Connect to the configured storage
+	repos, closeRepositories, err := newRepositories(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err := client.Disconnect(ctx); err != nil {
-			log.Printf("Failed to disconnect from MongoDB: %v", err)
-		}
-	}()
+	defer closeRepositories()
+
+	rateLimits, closeRateLimits, err := newRateLimits()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeRateLimits()
+
+	keys, err := newKeyManager()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tokenValidation := middleware.TokenValidation{
+		Issuer:   config.GetEnv("JWT_ISSUER", "golang-rest"),
+		Audience: config.GetEnv("JWT_AUDIENCE", "golang-rest"),
+		Leeway:   config.GetDuration("JWT_CLOCK_SKEW", 30*time.Second),
+	}
 
-	collection := client.Database(os.Getenv("MONGO_DATABASE")).Collection(os.Getenv("MONGO_COLLECTION"))
-	userRepository := mongo_repository.NewUserRepository(collection)
+	userNotifier, err := newNotifier()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	emailVerificationService := services.NewEmailVerificationService(repos.users, userNotifier, services.EmailVerificationConfig{
+		Secret:    secretFromEnv("EMAIL_VERIFICATION_SECRET", os.Getenv("JWT_SECRET")),
+		TokenTTL:  config.GetDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerifyURL: config.GetEnv("EMAIL_VERIFICATION_URL", "http://localhost:7002/verify-email"),
+	})
+	// Revocations are kept in memory, so each instance only refuses the tokens revoked through it;
+	// the tokens of revoked sessions are refused everywhere through the session store
+	revocations := memory_repository.NewTokenRevocationRepository()
+	authService := services.NewAuthService(repos.users, repos.refreshTokens, repos.loginAttempts, revocations, repos.mfa, repos.sessions, keys, services.AuthConfig{
+		Issuer:               tokenValidation.Issuer,
+		Audience:             []string{tokenValidation.Audience},
+		AccessTokenTTL:       config.GetDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:      config.GetDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		RequireVerifiedEmail: config.GetBool("REQUIRE_VERIFIED_EMAIL", false),
+		Lockout:              lockoutPolicy(),
+		MFAChallengeSecret:   secretFromEnv("MFA_CHALLENGE_SECRET", ""),
+		MFAChallengeTTL:      config.GetDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		RequireAdminMFA:      config.GetBool("REQUIRE_ADMIN_MFA", true),
+	})
+	policy := passwordPolicy()
+	userService := services.NewUserService(repos.users, emailVerificationService, authService, services.UserConfig{
+		AdminEmails:    config.GetList("ADMIN_EMAILS"),
+		PasswordPolicy: policy,
+	})
+	apiKeyService := services.NewAPIKeyService(repos.apiKeys)
+	sessionService := services.NewSessionService(repos.sessions, repos.refreshTokens, revocations, services.SessionConfig{
+		AccessTokenTTL: config.GetDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+	})
+	mfaService := services.NewMFAService(repos.users, repos.mfa, authService, services.MFAConfig{
+		Issuer:            config.GetEnv("MFA_ISSUER", tokenValidation.Issuer),
+		RecoveryCodeCount: 10,
+	})
+	identityProviders, err := newIdentityProviders(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	oidcService := services.NewOIDCService(repos.users, repos.identities, authService, identityProviders, services.OIDCConfig{
+		SessionSecret: secretFromEnv("OIDC_SESSION_SECRET", ""),
+		SessionTTL:    config.GetDuration("OIDC_SESSION_TTL", 10*time.Minute),
+	})
+	passwordService := services.NewPasswordService(repos.users, repos.resetTokens, userNotifier, authService, authService, services.PasswordConfig{
+		Policy:        policy,
+		ResetTokenTTL: config.GetDuration("PASSWORD_RESET_TTL", time.Hour),
+		ResetURL:      os.Getenv("PASSWORD_RESET_URL"),
+	})
+	userPurgeService := services.NewUserPurgeService(repos.users, repos.identities, repos.mfa, repos.sessions, repos.refreshTokens, repos.resetTokens, repos.loginAttempts)
+
+	auditRepository, err := newAuditRepository(repos.audit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Only what comes in through the transports and the purge job is recorded, the services calling each other are not
+	auditRecorder := audit.NewRecorder(auditRepository)
+	auditedUserService := audit.NewUserService(userService, auditRecorder)
 
 	// Setup middleware and routes
 	app.Use(middleware.Logger())
-	http.Setup(app, userRepository)
+	app.Use(middleware.RequestContext(ctx))
+	app.Use(middleware.RequestID())
+	app.Use(middleware.ClientInfo())
+	http.Setup(app,
+		auditedUserService,
+		audit.NewAuthService(authService, auditRecorder),
+		audit.NewPasswordService(passwordService, auditRecorder),
+		audit.NewEmailVerificationService(emailVerificationService, auditRecorder),
+		audit.NewAPIKeyService(apiKeyService, auditRecorder),
+		audit.NewOIDCService(oidcService, auditRecorder),
+		audit.NewMFAService(mfaService, auditRecorder),
+		audit.NewSessionService(sessionService, auditRecorder),
+		services.NewAuditService(auditRepository),
+		http.AccessTokens{Keys: keys, Validation: tokenValidation, Revocations: revocations, Sessions: repos.sessions}, rateLimits)
+
+	// Initialize a new gRPC server on the same service
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcadapter.RequestIDInterceptor(),
+		grpcadapter.AuthInterceptor(middleware.NewTokenAuthenticator(keys, tokenValidation, revocations, repos.sessions), apiKeyService, grpcadapter.PublicMethods),
+	))
+	grpcadapter.Setup(grpcServer, auditedUserService)
 
 	// Start background processes
-	background.StartUserLogger(ctx, &wg, userRepository)
+	background.StartUserLogger(ctx, &wg, repos.users)
+	background.StartUserPurger(ctx, &wg, audit.NewUserPurgeService(userPurgeService, auditRecorder),
+		config.GetDuration("USER_PURGE_RETENTION", 30*24*time.Hour),
+		config.GetDuration("USER_PURGE_INTERVAL", time.Hour),
+	)
 
 	// Channel to listen for OS signals
 	quit := make(chan os.Signal, 1)
@@ -62,12 +163,23 @@ func main() {
 
 	// Start the server in a separate goroutine
 	go func() {
-		if err := app.Listen(":7002"); err != nil {
+		if err := app.Listen(config.GetEnv("HTTP_ADDR", ":7002")); err != nil {
 			log.Printf("Failed to start server: %v", err)
 		}
 		close(shutdownComplete)
 	}()
 
+	// Start the gRPC server in a separate goroutine
+	grpcListener, err := net.Listen("tcp", config.GetEnv("GRPC_ADDR", ":7003"))
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	// Wait for OS signal
 	<-quit
 	log.Println("Shutting signal received, shutting down server...")
@@ -82,9 +194,68 @@ func main() {
 		log.Printf("Failed to shutdown the app: %v", err)
 	}
 
+	// Stop accepting gRPC calls and wait for in-flight ones, bounded by the same timeout
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
 	// Wait for background goroutines to finish
 	wg.Wait()
 
 	// Shutdown complete
 	log.Println("Application shutdown complete")
 }
+
+// passwordPolicy starts from domain.DefaultPasswordPolicy and lets every rule be overridden from the environment.
+func passwordPolicy() domain.PasswordPolicy {
+	policy := domain.DefaultPasswordPolicy
+	if minLength, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil {
+		policy.MinLength = minLength
+	}
+	policy.RequireUpper = config.GetBool("PASSWORD_REQUIRE_UPPER", policy.RequireUpper)
+	policy.RequireLower = config.GetBool("PASSWORD_REQUIRE_LOWER", policy.RequireLower)
+	policy.RequireDigit = config.GetBool("PASSWORD_REQUIRE_DIGIT", policy.RequireDigit)
+	policy.RequireSymbol = config.GetBool("PASSWORD_REQUIRE_SYMBOL", policy.RequireSymbol)
+	return policy
+}
+
+// lockoutPolicy starts from domain.DefaultLockoutPolicy and lets every limit be overridden from the environment.
+func lockoutPolicy() domain.LockoutPolicy {
+	policy := domain.DefaultLockoutPolicy
+	if maxFailures, err := strconv.Atoi(os.Getenv("LOGIN_MAX_ACCOUNT_FAILURES")); err == nil {
+		policy.MaxAccountFailures = maxFailures
+	}
+	if maxFailures, err := strconv.Atoi(os.Getenv("LOGIN_MAX_IP_FAILURES")); err == nil {
+		policy.MaxIPFailures = maxFailures
+	}
+	policy.LockoutDuration = config.GetDuration("LOGIN_LOCKOUT_DURATION", policy.LockoutDuration)
+	policy.FailureWindow = config.GetDuration("LOGIN_FAILURE_WINDOW", policy.FailureWindow)
+	policy.BaseDelay = config.GetDuration("LOGIN_BASE_DELAY", policy.BaseDelay)
+	policy.MaxDelay = config.GetDuration("LOGIN_MAX_DELAY", policy.MaxDelay)
+	return policy
+}
+
+// secretFromEnv reads the HMAC secret in key, or in fallback when key is unset. Without either it falls back
+// to a random secret, so whatever it signed stops working after a restart and on the other instances;
+// with APP_ENV=production it refuses to start instead.
+func secretFromEnv(key string, fallback string) []byte {
+	if secret := config.GetEnv(key, fallback); secret != "" {
+		return []byte(secret)
+	}
+	if config.GetEnv("APP_ENV", "development") == "production" {
+		log.Fatalf("%s is not set", key)
+	}
+	log.Printf("%s is not set, using a temporary secret", key)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal(err)
+	}
+	return secret
+}
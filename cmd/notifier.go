@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"golang-rest/internal/adapters/outbound/notifier"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/config"
+	"log"
+)
+
+// newNotifier picks how messages to users are delivered from NOTIFIER.
+func newNotifier() (ports.Notifier, error) {
+	switch kind := config.GetEnv("NOTIFIER", "log"); kind {
+	case "log":
+		return notifier.NewLogNotifier(log.Default()), nil
+	case "file":
+		return notifier.NewFileNotifier(config.GetEnv("NOTIFIER_FILE", "notifications.log")), nil
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", kind)
+	}
+}
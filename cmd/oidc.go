@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/config"
+	"golang-rest/internal/infrastructure/oidc"
+	"os"
+	"strings"
+)
+
+// newIdentityProviders discovers every provider named in OIDC_PROVIDERS, configured by OIDC_<NAME>_* variables.
+func newIdentityProviders(ctx context.Context) (map[string]ports.IdentityProvider, error) {
+	providers := make(map[string]ports.IdentityProvider)
+	for _, name := range config.GetList("OIDC_PROVIDERS") {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providerConfig := oidc.Config{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  config.GetEnv(prefix+"REDIRECT_URL", "http://localhost:7002/auth/"+name+"/callback"),
+			Scopes:       config.GetList(prefix + "SCOPES"),
+		}
+		if providerConfig.Issuer == "" || providerConfig.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+
+		provider, err := oidc.Discover(ctx, providerConfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		providers[name] = provider
+	}
+	return providers, nil
+}
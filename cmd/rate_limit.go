@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"golang-rest/internal/adapters/inbound/http"
+	"golang-rest/internal/infrastructure/config"
+	"golang-rest/internal/infrastructure/middleware"
+	"golang-rest/internal/infrastructure/ratelimit"
+	"log"
+	"strings"
+)
+
+// newRateLimits builds one limiter per route group from RATE_LIMIT_AUTH, RATE_LIMIT_READ and
+// RATE_LIMIT_WRITE, with counters kept where RATE_LIMIT_STORE says. "off" disables a group.
+func newRateLimits() (http.RateLimits, func(), error) {
+	store, closeStore, err := newRateLimitStore()
+	if err != nil {
+		return http.RateLimits{}, nil, err
+	}
+
+	var limits http.RateLimits
+	groups := []struct {
+		name     string
+		fallback string
+		key      middleware.KeyFunc
+		handler  *fiber.Handler
+	}{
+		{"auth", "sliding_window:10/1m", middleware.KeyByIP, &limits.Auth},
+		{"read", "token_bucket:300/1m", middleware.KeyByAPIKey(middleware.APIKeyHeader), &limits.Read},
+		{"write", "token_bucket:60/1m", middleware.KeyByAPIKey(middleware.APIKeyHeader), &limits.Write},
+	}
+	for _, group := range groups {
+		value := config.GetEnv("RATE_LIMIT_"+strings.ToUpper(group.name), group.fallback)
+		if value == "off" {
+			continue
+		}
+		policy, err := ratelimit.ParsePolicy(value)
+		if err != nil {
+			closeStore()
+			return http.RateLimits{}, nil, err
+		}
+		*group.handler = middleware.RateLimit(middleware.RateLimitConfig{Name: group.name, Policy: policy, Store: store, Key: group.key})
+	}
+	return limits, closeStore, nil
+}
+
+func newRateLimitStore() (ratelimit.Store, func(), error) {
+	switch kind := config.GetEnv("RATE_LIMIT_STORE", "memory"); kind {
+	case "memory":
+		return ratelimit.NewMemoryStore(), func() {}, nil
+	case "redis":
+		options, err := redis.ParseURL(config.GetEnv("REDIS_URL", "redis://localhost:6379/0"))
+		if err != nil {
+			return nil, nil, err
+		}
+		client := redis.NewClient(options)
+		closeClient := func() {
+			if err := client.Close(); err != nil {
+				log.Printf("Failed to close Redis client: %v", err)
+			}
+		}
+		return ratelimit.NewRedisStore(client, "ratelimit:"), closeClient, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", kind)
+	}
+}
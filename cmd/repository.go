@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/adapters/outbound/mongo_repository"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/config"
+	"log"
+	"os"
+	"time"
+)
+
+type repositories struct {
+	users         ports.UserRepositoryInterface
+	refreshTokens ports.RefreshTokenRepositoryInterface
+	resetTokens   ports.PasswordResetTokenRepositoryInterface
+	loginAttempts ports.LoginAttemptRepositoryInterface
+	apiKeys       ports.APIKeyRepositoryInterface
+	identities    ports.IdentityRepositoryInterface
+	mfa           ports.MFARepositoryInterface
+	sessions      ports.SessionRepositoryInterface
+	// audit is nil for backends without an audit store of their own, see newAuditRepository.
+	audit ports.AuditRepositoryInterface
+}
+
+// newRepositories picks the storage backend from USER_REPOSITORY and returns a cleanup func for it.
+func newRepositories(ctx context.Context) (*repositories, func(), error) {
+	timeout := config.GetDuration("REPOSITORY_TIMEOUT", 5*time.Second)
+
+	switch backend := config.GetEnv("USER_REPOSITORY", "mongo"); backend {
+	case "memory":
+		log.Println("Using in-memory user repository")
+		return &repositories{
+			users:         memory_repository.NewUserRepository(),
+			refreshTokens: memory_repository.NewRefreshTokenRepository(),
+			resetTokens:   memory_repository.NewPasswordResetTokenRepository(),
+			loginAttempts: memory_repository.NewLoginAttemptRepository(),
+			apiKeys:       memory_repository.NewAPIKeyRepository(),
+			identities:    memory_repository.NewIdentityRepository(),
+			mfa:           memory_repository.NewMFARepository(),
+			sessions:      memory_repository.NewSessionRepository(),
+			audit:         memory_repository.NewAuditRepository(),
+		}, func() {}, nil
+	case "mongo":
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_URI")))
+		if err != nil {
+			return nil, nil, err
+		}
+		disconnect := func() {
+			if err := client.Disconnect(context.Background()); err != nil {
+				log.Printf("Failed to disconnect from MongoDB: %v", err)
+			}
+		}
+
+		database := client.Database(os.Getenv("MONGO_DATABASE"))
+		return &repositories{
+			users:         mongo_repository.NewUserRepository(database.Collection(os.Getenv("MONGO_COLLECTION")), timeout),
+			refreshTokens: mongo_repository.NewRefreshTokenRepository(database.Collection(config.GetEnv("MONGO_REFRESH_TOKEN_COLLECTION", "refresh_tokens")), timeout),
+			resetTokens:   mongo_repository.NewPasswordResetTokenRepository(database.Collection(config.GetEnv("MONGO_PASSWORD_RESET_TOKEN_COLLECTION", "password_reset_tokens")), timeout),
+			loginAttempts: mongo_repository.NewLoginAttemptRepository(database.Collection(config.GetEnv("MONGO_LOGIN_ATTEMPT_COLLECTION", "login_attempts")), timeout),
+			apiKeys:       mongo_repository.NewAPIKeyRepository(database.Collection(config.GetEnv("MONGO_API_KEY_COLLECTION", "api_keys")), timeout),
+			identities:    mongo_repository.NewIdentityRepository(database.Collection(config.GetEnv("MONGO_IDENTITY_COLLECTION", "identities")), timeout),
+			mfa:           mongo_repository.NewMFARepository(database.Collection(config.GetEnv("MONGO_MFA_COLLECTION", "mfa")), timeout),
+			sessions:      mongo_repository.NewSessionRepository(database.Collection(config.GetEnv("MONGO_SESSION_COLLECTION", "sessions")), timeout),
+			audit:         mongo_repository.NewAuditRepository(database.Collection(config.GetEnv("MONGO_AUDIT_COLLECTION", "audit_events")), timeout),
+		}, disconnect, nil
+	case "sql":
+		db, err := gorm_repository.Open(config.GetEnv("SQL_DSN", "golang-rest.db"))
+		if err != nil {
+			return nil, nil, err
+		}
+		closeDB := func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		}
+
+		return &repositories{
+			users:         gorm_repository.NewUserRepository(db, timeout),
+			refreshTokens: gorm_repository.NewRefreshTokenRepository(db, timeout),
+			resetTokens:   gorm_repository.NewPasswordResetTokenRepository(db, timeout),
+			loginAttempts: gorm_repository.NewLoginAttemptRepository(db, timeout),
+			apiKeys:       gorm_repository.NewAPIKeyRepository(db, timeout),
+			identities:    gorm_repository.NewIdentityRepository(db, timeout),
+			mfa:           gorm_repository.NewMFARepository(db, timeout),
+			sessions:      gorm_repository.NewSessionRepository(db, timeout),
+		}, closeDB, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown USER_REPOSITORY %q", backend)
+	}
+}
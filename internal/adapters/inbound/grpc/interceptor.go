@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/internal/infrastructure/middleware"
+	"golang-rest/internal/infrastructure/requestid"
+	"golang-rest/proto/userpb"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"slices"
+	"strings"
+)
+
+// PublicMethods are served without credentials, like POST /register is.
+var PublicMethods = []string{userpb.UserService_CreateUser_FullMethodName}
+
+// RequestIDInterceptor is the gRPC counterpart of middleware.RequestID: it reads or makes up
+// an x-request-id, puts it into the context and echoes it in the response header.
+func RequestIDInterceptor() googlegrpc.UnaryServerInterceptor {
+	header := strings.ToLower(requestid.Header)
+	return func(ctx context.Context, req interface{}, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		id := requestid.Resolve(firstValue(md, header))
+		_ = googlegrpc.SetHeader(ctx, metadata.Pairs(header, id))
+		return handler(requestid.NewContext(ctx, id), req)
+	}
+}
+
+// AuthInterceptor is the gRPC counterpart of middleware.APIKeyAuth and middleware.Protected: it lets a call
+// in with an API key or a bearer token from the metadata and puts the caller's principal into the context.
+// Only the public methods go through without credentials. A nil apiKeys leaves API keys to fail as tokens.
+func AuthInterceptor(tokens middleware.TokenAuthenticator, apiKeys middleware.APIKeyAuthenticator, public []string) googlegrpc.UnaryServerInterceptor {
+	apiKeyHeader := strings.ToLower(middleware.APIKeyHeader)
+	return func(ctx context.Context, req interface{}, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization := firstValue(md, "authorization")
+		key := middleware.APIKeyFrom(authorization, firstValue(md, apiKeyHeader))
+		if authorization == "" && key == "" && slices.Contains(public, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		var principal *domain.Principal
+		var err error
+		if key != "" && apiKeys != nil {
+			principal, err = apiKeys.AuthenticateAPIKey(ctx, key)
+		} else {
+			principal, err = tokens.Authenticate(ctx, authorization)
+		}
+		if err != nil {
+			return nil, apierror.GRPCStatus(ctx, err)
+		}
+		return handler(domain.WithPrincipal(ctx, principal), req)
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
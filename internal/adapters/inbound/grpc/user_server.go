@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/proto/userpb"
+	googlegrpc "google.golang.org/grpc"
+)
+
+type UserServer struct {
+	userpb.UnimplementedUserServiceServer
+	userService ports.UserService
+}
+
+func Setup(server *googlegrpc.Server, userService ports.UserService) {
+	userpb.RegisterUserServiceServer(server, &UserServer{userService: userService})
+}
+
+func (s UserServer) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
+	if err := authorizeOwner(ctx, req.GetId(), domain.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByID(ctx, req.GetId())
+	if err != nil {
+		return nil, apierror.GRPCStatus(ctx, err)
+	}
+
+	return &userpb.GetUserResponse{Id: user.ID.Hex(), Name: user.Name, Email: user.Email}, nil
+}
+
+func (s UserServer) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
+	user, err := s.userService.RegisterUser(ctx, ports.RegisterUserInput{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(ctx, err)
+	}
+
+	return &userpb.CreateUserResponse{Id: user.ID.Hex()}, nil
+}
+
+func (s UserServer) GetAllUsers(ctx context.Context, req *userpb.GetAllUsersRequest) (*userpb.GetAllUsersResponse, error) {
+	if err := authorize(ctx, domain.PermissionUsersList); err != nil {
+		return nil, err
+	}
+
+	query := domain.UserQuery{
+		Limit:        int(req.GetLimit()),
+		Offset:       int(req.GetOffset()),
+		SortBy:       req.GetSortBy(),
+		SortDesc:     req.GetSortDesc(),
+		EmailPrefix:  req.GetEmailPrefix(),
+		NameContains: req.GetNameContains(),
+	}
+	if req.GetCreatedAfter() != nil {
+		createdAfter := req.GetCreatedAfter().AsTime()
+		query.CreatedAfter = &createdAfter
+	}
+	if req.GetCreatedBefore() != nil {
+		createdBefore := req.GetCreatedBefore().AsTime()
+		query.CreatedBefore = &createdBefore
+	}
+
+	page, err := s.userService.GetAllUsers(ctx, query)
+	if err != nil {
+		return nil, apierror.GRPCStatus(ctx, err)
+	}
+
+	response := &userpb.GetAllUsersResponse{
+		Users:  make([]*userpb.User, 0, len(page.Users)),
+		Total:  page.Total,
+		Limit:  int32(page.Limit),
+		Offset: int32(page.Offset),
+	}
+	for _, user := range page.Users {
+		response.Users = append(response.Users, &userpb.User{Id: user.ID.Hex(), Name: user.Name, Email: user.Email})
+	}
+	if page.HasNext() {
+		response.NextOffset = int32(page.Offset + len(page.Users))
+	}
+	return response, nil
+}
+
+func (s UserServer) UpdateUserByID(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	if err := authorizeOwner(ctx, req.GetId(), domain.PermissionUsersUpdate); err != nil {
+		return nil, err
+	}
+
+	// proto3 strings carry no presence, so an empty value means "leave unchanged".
+	// The request has no version either, the update applies to whatever version is current.
+	var input ports.UpdateUserInput
+	if name := req.GetName(); name != "" {
+		input.Name = &name
+	}
+	if email := req.GetEmail(); email != "" {
+		input.Email = &email
+	}
+
+	user, err := s.userService.UpdateUserByID(ctx, req.GetId(), input)
+	if err != nil {
+		return nil, apierror.GRPCStatus(ctx, err)
+	}
+
+	return &userpb.UpdateUserResponse{Id: user.ID.Hex(), Name: user.Name, Email: user.Email}, nil
+}
+
+func (s UserServer) DeleteUserByID(ctx context.Context, req *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	if err := authorizeOwner(ctx, req.GetId(), domain.PermissionUsersDelete); err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.DeleteUserByID(ctx, req.GetId()); err != nil {
+		return nil, apierror.GRPCStatus(ctx, err)
+	}
+
+	return &userpb.DeleteUserResponse{Success: true}, nil
+}
+
+// authorize is the gRPC counterpart of middleware.RequirePermission, AuthInterceptor put the principal into ctx.
+func authorize(ctx context.Context, permission domain.Permission) error {
+	principal := domain.PrincipalFrom(ctx)
+	if principal == nil || !principal.HasPermission(permission) {
+		return apierror.GRPCStatus(ctx, domain.ErrPermissionDenied)
+	}
+	return nil
+}
+
+// authorizeOwner is the gRPC counterpart of middleware.RequireOwnerOrPermission.
+func authorizeOwner(ctx context.Context, userID string, permission domain.Permission) error {
+	principal := domain.PrincipalFrom(ctx)
+	if principal == nil || !principal.CanAccessUser(userID, permission) {
+		return apierror.GRPCStatus(ctx, domain.ErrNotOwner)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"time"
+)
+
+type APIKeyHandler struct {
+	apiKeyService ports.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService ports.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (a APIKeyHandler) CreateAPIKey(ctx *fiber.Ctx) error {
+	var input struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	created, err := a.apiKeyService.CreateAPIKey(ctx.UserContext(), ports.CreateAPIKeyInput{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to create API key")
+	}
+
+	// The plain key is not stored, this is the only time anybody sees it
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"key":     created.Key,
+		"api_key": created.APIKey,
+	})
+}
+
+func (a APIKeyHandler) ListAPIKeys(ctx *fiber.Ctx) error {
+	keys, err := a.apiKeyService.ListAPIKeys(ctx.UserContext())
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to get API keys")
+	}
+
+	return ctx.JSON(fiber.Map{"api_keys": keys})
+}
+
+func (a APIKeyHandler) RevokeAPIKey(ctx *fiber.Ctx) error {
+	if err := a.apiKeyService.RevokeAPIKey(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to revoke API key")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "API key revoked successfully"})
+}
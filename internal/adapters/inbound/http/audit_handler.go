@@ -0,0 +1,71 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+)
+
+type AuditHandler struct {
+	auditService ports.AuditService
+}
+
+func NewAuditHandler(auditService ports.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+func (a AuditHandler) ListAuditEvents(ctx *fiber.Ctx) error {
+	query, err := parseAuditQuery(ctx)
+	if err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	page, err := a.auditService.ListAuditEvents(ctx.UserContext(), query)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to get audit events")
+	}
+
+	response := fiber.Map{
+		"events": page.Events,
+		"total":  page.Total,
+		"limit":  page.Limit,
+		"offset": page.Offset,
+		"next":   nil,
+		"prev":   nil,
+	}
+	if page.HasNext() {
+		response["next"] = pageLink(ctx, page.Offset+page.Limit)
+	}
+	if page.Offset > 0 {
+		response["prev"] = pageLink(ctx, max(page.Offset-page.Limit, 0))
+	}
+	return ctx.JSON(response)
+}
+
+// parseAuditQuery reads GET /audit parameters: limit, offset, action, outcome, actor_id,
+// target_id, target_email, from and to (RFC 3339).
+func parseAuditQuery(ctx *fiber.Ctx) (domain.AuditQuery, error) {
+	query := domain.AuditQuery{
+		Action:      ctx.Query("action"),
+		Outcome:     ctx.Query("outcome"),
+		ActorID:     ctx.Query("actor_id"),
+		TargetID:    ctx.Query("target_id"),
+		TargetEmail: ctx.Query("target_email"),
+	}
+	var err error
+
+	if query.Limit, err = queryInt(ctx, "limit"); err != nil {
+		return query, err
+	}
+	if query.Offset, err = queryInt(ctx, "offset"); err != nil {
+		return query, err
+	}
+	if query.From, err = queryTime(ctx, "from"); err != nil {
+		return query, err
+	}
+	if query.To, err = queryTime(ctx, "to"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"time"
+)
+
+var (
+	errMissingRefreshToken = domain.NewError(domain.ErrInvalidInput, "missing_refresh_token", "missing refresh token")
+	errMissingMFAToken     = domain.NewError(domain.ErrInvalidInput, "missing_mfa_token", "missing mfa token")
+)
+
+type AuthHandler struct {
+	authService ports.AuthService
+}
+
+func NewAuthHandler(authService ports.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+func (a AuthHandler) LoginUser(ctx *fiber.Ctx) error {
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	tokens, err := a.authService.LoginUser(ctx.UserContext(), input.Email, input.Password)
+	return loginResponse(ctx, tokens, err)
+}
+
+func (a AuthHandler) VerifyMFA(ctx *fiber.Ctx) error {
+	var input struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+	if input.MFAToken == "" {
+		return apierror.Respond(ctx, errMissingMFAToken, "")
+	}
+
+	tokens, err := a.authService.VerifyMFA(ctx.UserContext(), input.MFAToken, input.Code)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Server error!")
+	}
+
+	return ctx.JSON(tokenResponse(tokens))
+}
+
+func (a AuthHandler) RefreshToken(ctx *fiber.Ctx) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+	if input.RefreshToken == "" {
+		return apierror.Respond(ctx, errMissingRefreshToken, "")
+	}
+
+	tokens, err := a.authService.RefreshToken(ctx.UserContext(), input.RefreshToken)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Server error!")
+	}
+
+	return ctx.JSON(tokenResponse(tokens))
+}
+
+func (a AuthHandler) Logout(ctx *fiber.Ctx) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+	if input.RefreshToken == "" {
+		return apierror.Respond(ctx, errMissingRefreshToken, "")
+	}
+
+	if err := a.authService.Logout(ctx.UserContext(), input.RefreshToken); err != nil {
+		return apierror.Respond(ctx, err, "Server error!")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Logged out successfully"})
+}
+
+// tokenResponse keeps the original "token" key for existing clients next to the OAuth2-style fields.
+func tokenResponse(tokens *ports.TokenPair) fiber.Map {
+	return fiber.Map{
+		"token":         tokens.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(time.Until(tokens.AccessTokenExpiresAt).Seconds()),
+		"refresh_token": tokens.RefreshToken,
+	}
+}
+
+// loginResponse answers a first login step: with tokens, or with the token for POST /login/mfa.
+func loginResponse(ctx *fiber.Ctx, tokens *ports.TokenPair, err error) error {
+	var challenge *domain.MFARequiredError
+	if errors.As(err, &challenge) {
+		return ctx.JSON(fiber.Map{
+			"mfa_required": true,
+			"mfa_token":    challenge.Token,
+			"expires_in":   int(time.Until(challenge.ExpiresAt).Seconds()),
+		})
+	}
+	if err != nil {
+		return apierror.Respond(ctx, err, "Server error!")
+	}
+
+	return ctx.JSON(tokenResponse(tokens))
+}
+
+func (a AuthHandler) UnlockUser(ctx *fiber.Ctx) error {
+	if err := a.authService.UnlockUser(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to unlock user")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "User unlocked successfully"})
+}
+
+func (a AuthHandler) RevokeUserTokens(ctx *fiber.Ctx) error {
+	if err := a.authService.RevokeUserTokens(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to log out user")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "User logged out successfully"})
+}
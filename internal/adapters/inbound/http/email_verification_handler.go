@@ -0,0 +1,58 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/internal/infrastructure/middleware"
+)
+
+var errMissingVerificationToken = domain.NewError(domain.ErrInvalidInput, "missing_token", "missing token")
+
+type EmailVerificationHandler struct {
+	emailVerificationService ports.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(emailVerificationService ports.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{emailVerificationService: emailVerificationService}
+}
+
+func (e EmailVerificationHandler) VerifyEmail(ctx *fiber.Ctx) error {
+	token := ctx.Query("token")
+	if token == "" {
+		return apierror.Respond(ctx, errMissingVerificationToken, "")
+	}
+
+	if err := e.emailVerificationService.VerifyEmail(ctx.UserContext(), token); err != nil {
+		return apierror.Respond(ctx, err, "Failed to verify email")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Email verified successfully"})
+}
+
+func (e EmailVerificationHandler) RequestVerificationEmail(ctx *fiber.Ctx) error {
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	if err := e.emailVerificationService.RequestVerificationEmail(ctx.UserContext(), input.Email); err != nil {
+		return apierror.Respond(ctx, err, "Failed to send verification email")
+	}
+
+	// The same answer whether or not the account exists or is verified already
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "If the account needs verifying, a verification email has been sent"})
+}
+
+func (e EmailVerificationHandler) ResendVerificationEmail(ctx *fiber.Ctx) error {
+	principal := middleware.PrincipalFrom(ctx)
+
+	if err := e.emailVerificationService.ResendVerificationEmail(ctx.UserContext(), principal.UserID); err != nil {
+		return apierror.Respond(ctx, err, "Failed to send verification email")
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Verification email sent"})
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/infrastructure/jwtkeys"
+)
+
+type JWKSHandler struct {
+	keys *jwtkeys.KeyManager
+}
+
+func NewJWKSHandler(keys *jwtkeys.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public keys, so other services can check our tokens on their own.
+// The short cache lets them pick up a rotated key within minutes.
+func (j JWKSHandler) GetJWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(j.keys.JWKS())
+}
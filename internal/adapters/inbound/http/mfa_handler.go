@@ -0,0 +1,81 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+)
+
+type MFAHandler struct {
+	mfaService ports.MFAService
+}
+
+func NewMFAHandler(mfaService ports.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+type mfaCodeInput struct {
+	Code string `json:"code"`
+}
+
+func (m MFAHandler) EnrollTOTP(ctx *fiber.Ctx) error {
+	enrollment, err := m.mfaService.EnrollTOTP(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to start MFA enrollment")
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"secret":           enrollment.Secret,
+		"provisioning_uri": enrollment.ProvisioningURI,
+	})
+}
+
+func (m MFAHandler) ConfirmTOTP(ctx *fiber.Ctx) error {
+	var input mfaCodeInput
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	codes, err := m.mfaService.ConfirmTOTP(ctx.UserContext(), ctx.Params("id"), input.Code)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to confirm MFA enrollment")
+	}
+
+	// The recovery codes are only stored hashed, this is the only time anybody sees them
+	return ctx.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (m MFAHandler) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var input mfaCodeInput
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	codes, err := m.mfaService.RegenerateRecoveryCodes(ctx.UserContext(), ctx.Params("id"), input.Code)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to create recovery codes")
+	}
+
+	return ctx.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (m MFAHandler) DisableMFA(ctx *fiber.Ctx) error {
+	var input mfaCodeInput
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	if err := m.mfaService.DisableMFA(ctx.UserContext(), ctx.Params("id"), input.Code); err != nil {
+		return apierror.Respond(ctx, err, "Failed to turn MFA off")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "MFA turned off successfully"})
+}
+
+func (m MFAHandler) ResetMFA(ctx *fiber.Ctx) error {
+	if err := m.mfaService.ResetMFA(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to reset MFA")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "MFA reset successfully"})
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"strings"
+	"time"
+)
+
+const oidcSessionCookie = "oidc_session"
+
+type OIDCHandler struct {
+	oidcService ports.OIDCService
+}
+
+func NewOIDCHandler(oidcService ports.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+func (o OIDCHandler) Login(ctx *fiber.Ctx) error {
+	provider := strings.Clone(ctx.Params("provider"))
+
+	authorization, err := o.oidcService.StartLogin(ctx.UserContext(), provider)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to start login")
+	}
+
+	// The session only has to reach the callback of the same provider; Lax still sends it on the redirect back
+	ctx.Cookie(&fiber.Cookie{
+		Name:     oidcSessionCookie,
+		Value:    authorization.Session,
+		Path:     "/auth/" + provider,
+		HTTPOnly: true,
+		Secure:   ctx.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return ctx.Redirect(authorization.URL, fiber.StatusFound)
+}
+
+func (o OIDCHandler) Callback(ctx *fiber.Ctx) error {
+	provider := strings.Clone(ctx.Params("provider"))
+	session := ctx.Cookies(oidcSessionCookie)
+
+	// The session is single use, whatever the outcome
+	ctx.Cookie(&fiber.Cookie{
+		Name:     oidcSessionCookie,
+		Path:     "/auth/" + provider,
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		Secure:   ctx.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if ctx.Query("error") != "" {
+		return apierror.Respond(ctx, domain.ErrOIDCLoginFailed, "")
+	}
+
+	tokens, err := o.oidcService.CompleteLogin(ctx.UserContext(), provider, session, ctx.Query("state"), ctx.Query("code"))
+	return loginResponse(ctx, tokens, err)
+}
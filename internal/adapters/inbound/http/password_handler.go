@@ -0,0 +1,64 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+)
+
+type PasswordHandler struct {
+	passwordService ports.PasswordService
+}
+
+func NewPasswordHandler(passwordService ports.PasswordService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+func (p PasswordHandler) ChangePassword(ctx *fiber.Ctx) error {
+	var input struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	err := p.passwordService.ChangePassword(ctx.UserContext(), ctx.Params("id"), input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to change password")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Password changed successfully"})
+}
+
+func (p PasswordHandler) ForgotPassword(ctx *fiber.Ctx) error {
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	if err := p.passwordService.RequestPasswordReset(ctx.UserContext(), input.Email); err != nil {
+		return apierror.Respond(ctx, err, "Failed to request password reset")
+	}
+
+	// The same answer whether or not the account exists
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "If the account exists, a reset link has been sent"})
+}
+
+func (p PasswordHandler) ResetPassword(ctx *fiber.Ctx) error {
+	var input struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	if err := p.passwordService.ResetPassword(ctx.UserContext(), input.Token, input.Password); err != nil {
+		return apierror.Respond(ctx, err, "Failed to reset password")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Password reset successfully"})
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"strings"
+)
+
+var errInvalidBody = domain.NewError(domain.ErrInvalidInput, "invalid_body", "cannot parse body")
+
+// parseBody decodes a JSON body and refuses fields the payload does not define.
+// Unknown or mistyped fields come back as a *domain.ValidationError, anything else as errInvalidBody.
+func parseBody(ctx *fiber.Ctx, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(ctx.Body()))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(out)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		v := domain.ValidationError{}
+		v.Add(typeErr.Field, domain.ValidationInvalidType, "must be a "+typeErr.Type.String())
+		return &v
+	}
+	// encoding/json has no typed error for unknown fields, only this message
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		v := domain.ValidationError{}
+		v.Add(strings.Trim(field, `"`), domain.ValidationUnknownField, "is not allowed")
+		return &v
+	}
+	return errInvalidBody
+}
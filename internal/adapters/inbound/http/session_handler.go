@@ -0,0 +1,40 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+)
+
+type SessionHandler struct {
+	sessionService ports.SessionService
+}
+
+func NewSessionHandler(sessionService ports.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+func (s SessionHandler) ListSessions(ctx *fiber.Ctx) error {
+	sessions, err := s.sessionService.ListSessions(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to get sessions")
+	}
+
+	return ctx.JSON(fiber.Map{"sessions": sessions})
+}
+
+func (s SessionHandler) RevokeSession(ctx *fiber.Ctx) error {
+	if err := s.sessionService.RevokeSession(ctx.UserContext(), ctx.Params("id"), ctx.Params("sid")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to revoke session")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Session revoked successfully"})
+}
+
+func (s SessionHandler) RevokeOtherSessions(ctx *fiber.Ctx) error {
+	if err := s.sessionService.RevokeOtherSessions(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return apierror.Respond(ctx, err, "Failed to revoke sessions")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "Sessions revoked successfully"})
+}
@@ -2,37 +2,379 @@ package http
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
 	"golang-rest/internal/core/ports"
-	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/internal/infrastructure/jwtkeys"
 	"golang-rest/internal/infrastructure/middleware"
+	"strconv"
+	"strings"
 )
 
-func Setup(app *fiber.App, userRepository ports.UserRepositoryInterface) {
-	userHandlerService := services.NewUserHandlerService(userRepository)
+type UserHandler struct {
+	userService ports.UserService
+}
+
+func NewUserHandler(userService ports.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// AccessTokens is how Protected checks bearer tokens, its keys are also published as the JWKS.
+type AccessTokens struct {
+	Keys       *jwtkeys.KeyManager
+	Validation middleware.TokenValidation
+	// Revocations is optional; without it a token stays valid until it expires.
+	Revocations ports.TokenRevocationRepositoryInterface
+	// Sessions is optional; with it the tokens of a revoked session are refused on every instance.
+	Sessions ports.SessionRepositoryInterface
+}
+
+// RateLimits holds the limiter for each route group, a nil one leaves the group unlimited.
+type RateLimits struct {
+	// Auth covers the public register, login, token, password and verification routes.
+	Auth  fiber.Handler
+	Read  fiber.Handler
+	Write fiber.Handler
+}
+
+// Setup accepts API keys next to bearer tokens on the protected routes when apiKeyService is not nil,
+// and only serves the OIDC login, MFA enrollment, session and audit routes when oidcService, mfaService,
+// sessionService and auditService are not nil.
+func Setup(app *fiber.App, userService ports.UserService, authService ports.AuthService, passwordService ports.PasswordService, emailVerificationService ports.EmailVerificationService, apiKeyService ports.APIKeyService, oidcService ports.OIDCService, mfaService ports.MFAService, sessionService ports.SessionService, auditService ports.AuditService, tokens AccessTokens, limits RateLimits) {
+	userHandler := NewUserHandler(userService)
+	authHandler := NewAuthHandler(authService)
+	passwordHandler := NewPasswordHandler(passwordService)
+	emailVerificationHandler := NewEmailVerificationHandler(emailVerificationService)
+	jwksHandler := NewJWKSHandler(tokens.Keys)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+	apiKeyAuth := orNext(nil)
+	if apiKeyService != nil {
+		apiKeyAuth = middleware.APIKeyAuth(apiKeyService)
+	}
+	tokenAuthenticator := middleware.NewTokenAuthenticator(tokens.Keys, tokens.Validation, tokens.Revocations, tokens.Sessions)
+	protected := middleware.Protected(tokenAuthenticator)
+	optionalAuth := middleware.OptionalAuth(tokenAuthenticator)
+	authLimit, readLimit, writeLimit := orNext(limits.Auth), orNext(limits.Read), orNext(limits.Write)
+
+	app.Get("/.well-known/jwks.json", func(ctx *fiber.Ctx) error {
+		return jwksHandler.GetJWKS(ctx)
+	})
+
+	app.Post("/register", authLimit, func(ctx *fiber.Ctx) error {
+		return userHandler.RegisterUser(ctx)
+	})
+
+	app.Post("/login", authLimit, func(ctx *fiber.Ctx) error {
+		return authHandler.LoginUser(ctx)
+	})
+
+	app.Post("/login/mfa", authLimit, func(ctx *fiber.Ctx) error {
+		return authHandler.VerifyMFA(ctx)
+	})
+
+	app.Post("/token/refresh", authLimit, func(ctx *fiber.Ctx) error {
+		return authHandler.RefreshToken(ctx)
+	})
+
+	app.Post("/logout", authLimit, optionalAuth, func(ctx *fiber.Ctx) error {
+		return authHandler.Logout(ctx)
+	})
+
+	app.Post("/password/forgot", authLimit, func(ctx *fiber.Ctx) error {
+		return passwordHandler.ForgotPassword(ctx)
+	})
+
+	app.Post("/password/reset", authLimit, func(ctx *fiber.Ctx) error {
+		return passwordHandler.ResetPassword(ctx)
+	})
+
+	app.Get("/verify-email", authLimit, func(ctx *fiber.Ctx) error {
+		return emailVerificationHandler.VerifyEmail(ctx)
+	})
+
+	app.Post("/verify-email/request", authLimit, func(ctx *fiber.Ctx) error {
+		return emailVerificationHandler.RequestVerificationEmail(ctx)
+	})
+
+	if oidcService != nil {
+		oidcHandler := NewOIDCHandler(oidcService)
+
+		app.Get("/auth/:provider/login", authLimit, func(ctx *fiber.Ctx) error {
+			return oidcHandler.Login(ctx)
+		})
+
+		app.Get("/auth/:provider/callback", authLimit, func(ctx *fiber.Ctx) error {
+			return oidcHandler.Callback(ctx)
+		})
+	}
+
+	app.Post("/verify-email/resend", apiKeyAuth, protected, writeLimit, func(ctx *fiber.Ctx) error {
+		return emailVerificationHandler.ResendVerificationEmail(ctx)
+	})
+
+	app.Get("/users", apiKeyAuth, protected, readLimit, middleware.RequirePermission(domain.PermissionUsersList), func(ctx *fiber.Ctx) error {
+		return userHandler.GetAllUsers(ctx)
+	})
+
+	app.Get("/users/:id", apiKeyAuth, protected, readLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersRead), func(ctx *fiber.Ctx) error {
+		return userHandler.GetUserByID(ctx)
+	})
+
+	app.Put("/users/:id", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+		return userHandler.UpdateUserByID(ctx)
+	})
+
+	app.Delete("/users/:id", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersDelete), func(ctx *fiber.Ctx) error {
+		return userHandler.DeleteUserByID(ctx)
+	})
+
+	app.Post("/users/:id/restore", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionUsersRestore), func(ctx *fiber.Ctx) error {
+		return userHandler.RestoreUserByID(ctx)
+	})
+
+	app.Put("/users/:id/password", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+		return passwordHandler.ChangePassword(ctx)
+	})
+
+	app.Put("/users/:id/roles/:role", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionRolesManage), func(ctx *fiber.Ctx) error {
+		return userHandler.GrantRole(ctx)
+	})
+
+	app.Delete("/users/:id/roles/:role", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionRolesManage), func(ctx *fiber.Ctx) error {
+		return userHandler.RevokeRole(ctx)
+	})
 
-	app.Post("/register", func(ctx *fiber.Ctx) error {
-		return userHandlerService.RegisterUser(ctx)
+	app.Delete("/users/:id/lockout", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionUsersUnlock), func(ctx *fiber.Ctx) error {
+		return authHandler.UnlockUser(ctx)
 	})
 
-	app.Post("/login", func(ctx *fiber.Ctx) error {
-		return userHandlerService.LoginUser(ctx)
+	app.Post("/users/:id/logout", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionSessionsRevoke), func(ctx *fiber.Ctx) error {
+		return authHandler.RevokeUserTokens(ctx)
 	})
 
-	app.Use(middleware.Protected())
+	if mfaService != nil {
+		mfaHandler := NewMFAHandler(mfaService)
 
-	app.Get("/users", middleware.Protected(), func(ctx *fiber.Ctx) error {
-		return userHandlerService.GetAllUsers(ctx)
+		app.Post("/users/:id/mfa/totp", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+			return mfaHandler.EnrollTOTP(ctx)
+		})
+
+		app.Post("/users/:id/mfa/totp/confirm", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+			return mfaHandler.ConfirmTOTP(ctx)
+		})
+
+		app.Post("/users/:id/mfa/recovery-codes", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+			return mfaHandler.RegenerateRecoveryCodes(ctx)
+		})
+
+		app.Delete("/users/:id/mfa", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersUpdate), func(ctx *fiber.Ctx) error {
+			return mfaHandler.DisableMFA(ctx)
+		})
+
+		app.Post("/users/:id/mfa/reset", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionMFAReset), func(ctx *fiber.Ctx) error {
+			return mfaHandler.ResetMFA(ctx)
+		})
+	}
+
+	if sessionService != nil {
+		sessionHandler := NewSessionHandler(sessionService)
+
+		app.Get("/users/:id/sessions", apiKeyAuth, protected, readLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionUsersRead), func(ctx *fiber.Ctx) error {
+			return sessionHandler.ListSessions(ctx)
+		})
+
+		app.Delete("/users/:id/sessions", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionSessionsRevoke), func(ctx *fiber.Ctx) error {
+			return sessionHandler.RevokeOtherSessions(ctx)
+		})
+
+		app.Delete("/users/:id/sessions/:sid", apiKeyAuth, protected, writeLimit, middleware.RequireOwnerOrPermission("id", domain.PermissionSessionsRevoke), func(ctx *fiber.Ctx) error {
+			return sessionHandler.RevokeSession(ctx)
+		})
+	}
+
+	if auditService != nil {
+		auditHandler := NewAuditHandler(auditService)
+
+		app.Get("/audit", apiKeyAuth, protected, readLimit, middleware.RequirePermission(domain.PermissionAuditRead), func(ctx *fiber.Ctx) error {
+			return auditHandler.ListAuditEvents(ctx)
+		})
+	}
+
+	app.Post("/api-keys", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionAPIKeysManage), func(ctx *fiber.Ctx) error {
+		return apiKeyHandler.CreateAPIKey(ctx)
 	})
 
-	app.Get("/users/:id", middleware.Protected(), func(ctx *fiber.Ctx) error {
-		return userHandlerService.GetUserByID(ctx)
+	app.Get("/api-keys", apiKeyAuth, protected, readLimit, middleware.RequirePermission(domain.PermissionAPIKeysManage), func(ctx *fiber.Ctx) error {
+		return apiKeyHandler.ListAPIKeys(ctx)
 	})
 
-	app.Put("/users/:id", middleware.Protected(), func(ctx *fiber.Ctx) error {
-		return userHandlerService.UpdateUserByID(ctx)
+	app.Delete("/api-keys/:id", apiKeyAuth, protected, writeLimit, middleware.RequirePermission(domain.PermissionAPIKeysManage), func(ctx *fiber.Ctx) error {
+		return apiKeyHandler.RevokeAPIKey(ctx)
 	})
+}
 
-	app.Delete("/users/:id", middleware.Protected(), func(ctx *fiber.Ctx) error {
-		return userHandlerService.DeleteUserByID(ctx)
+func orNext(handler fiber.Handler) fiber.Handler {
+	if handler != nil {
+		return handler
+	}
+	return func(ctx *fiber.Ctx) error {
+		return ctx.Next()
+	}
+}
+
+func (u UserHandler) RegisterUser(ctx *fiber.Ctx) error {
+	var input struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := parseBody(ctx, &input); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	_, err := u.userService.RegisterUser(ctx.UserContext(), ports.RegisterUserInput{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+	})
+	if err != nil {
+		return apierror.Respond(ctx, err, "Cannot create user!")
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "User created successfully!",
 	})
 }
+
+func (u UserHandler) GetAllUsers(ctx *fiber.Ctx) error {
+	principal := middleware.PrincipalFrom(ctx)
+
+	query, err := parseUserQuery(ctx)
+	if err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	page, err := u.userService.GetAllUsers(ctx.UserContext(), query)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to get users!")
+	}
+
+	response := fiber.Map{
+		"user_id": principal.UserID,
+		"users":   page.Users,
+		"total":   page.Total,
+		"limit":   page.Limit,
+		"offset":  page.Offset,
+		"next":    nil,
+		"prev":    nil,
+	}
+	if page.HasNext() {
+		response["next"] = pageLink(ctx, page.Offset+page.Limit)
+	}
+	if page.Offset > 0 {
+		response["prev"] = pageLink(ctx, max(page.Offset-page.Limit, 0))
+	}
+	return ctx.JSON(response)
+}
+
+func (u UserHandler) GetUserByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	user, err := u.userService.GetUserByID(ctx.UserContext(), id)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to get user")
+	}
+
+	return respondUser(ctx, user)
+}
+
+func (u UserHandler) UpdateUserByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	// Pointers tell a missing field apart from an empty one
+	var body struct {
+		Name  *string `json:"name"`
+		Email *string `json:"email"`
+	}
+	if err := parseBody(ctx, &body); err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	version, err := parseIfMatch(ctx)
+	if err != nil {
+		return apierror.Respond(ctx, err, "")
+	}
+
+	input := ports.UpdateUserInput{Name: body.Name, Email: body.Email, Version: version}
+	user, err := u.userService.UpdateUserByID(ctx.UserContext(), id, input)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to update user")
+	}
+
+	return respondUser(ctx, user)
+}
+
+func (u UserHandler) DeleteUserByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := u.userService.DeleteUserByID(ctx.UserContext(), id)
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to delete user")
+	}
+
+	return ctx.JSON(fiber.Map{"message": "User deleted successfully"})
+}
+
+func (u UserHandler) RestoreUserByID(ctx *fiber.Ctx) error {
+	user, err := u.userService.RestoreUserByID(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to restore user")
+	}
+
+	return respondUser(ctx, user)
+}
+
+func (u UserHandler) GrantRole(ctx *fiber.Ctx) error {
+	user, err := u.userService.GrantRole(ctx.UserContext(), ctx.Params("id"), ctx.Params("role"))
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to update roles")
+	}
+
+	return respondUser(ctx, user)
+}
+
+func (u UserHandler) RevokeRole(ctx *fiber.Ctx) error {
+	user, err := u.userService.RevokeRole(ctx.UserContext(), ctx.Params("id"), ctx.Params("role"))
+	if err != nil {
+		return apierror.Respond(ctx, err, "Failed to update roles")
+	}
+
+	return respondUser(ctx, user)
+}
+
+// respondUser sends the user with its version as ETag, which PUT expects back in If-Match.
+func respondUser(ctx *fiber.Ctx, user *domain.User) error {
+	ctx.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatInt(user.Version, 10)))
+	return ctx.JSON(user)
+}
+
+// parseIfMatch reads the single strong ETag a PUT has to send. "*" returns nil and updates whatever
+// version is current; a weak or malformed tag never matches.
+func parseIfMatch(ctx *fiber.Ctx) (*int64, error) {
+	header := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return nil, domain.ErrIfMatchRequired
+	}
+	if header == "*" {
+		return nil, nil
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		return nil, domain.ErrVersionMismatch
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return nil, domain.ErrVersionMismatch
+	}
+	return &version, nil
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseUserQuery reads GET /users parameters:
+// limit, offset, sort (name, email or create_at, prefixed with "-" for descending),
+// email_prefix, name_contains, created_after and created_before (RFC 3339).
+func parseUserQuery(ctx *fiber.Ctx) (domain.UserQuery, error) {
+	var query domain.UserQuery
+	var err error
+
+	if query.Limit, err = queryInt(ctx, "limit"); err != nil {
+		return query, err
+	}
+	if query.Offset, err = queryInt(ctx, "offset"); err != nil {
+		return query, err
+	}
+
+	sort := ctx.Query("sort")
+	query.SortDesc = strings.HasPrefix(sort, "-")
+	query.SortBy = strings.TrimPrefix(sort, "-")
+
+	query.EmailPrefix = ctx.Query("email_prefix")
+	query.NameContains = ctx.Query("name_contains")
+
+	if query.CreatedAfter, err = queryTime(ctx, "created_after"); err != nil {
+		return query, err
+	}
+	if query.CreatedBefore, err = queryTime(ctx, "created_before"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+func queryInt(ctx *fiber.Ctx, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", domain.ErrInvalidQuery, key)
+	}
+	return number, nil
+}
+
+func queryTime(ctx *fiber.Ctx, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", domain.ErrInvalidQuery, key)
+	}
+	return &parsed, nil
+}
+
+// pageLink rebuilds the current request URL with a different offset, keeping every other parameter.
+func pageLink(ctx *fiber.Ctx, offset int) string {
+	args := fiber.AcquireArgs()
+	defer fiber.ReleaseArgs(args)
+
+	ctx.Request().URI().QueryArgs().CopyTo(args)
+	args.Set("offset", strconv.Itoa(offset))
+	return ctx.Path() + "?" + args.String()
+}
@@ -0,0 +1,92 @@
+package file_repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// AuditRepository appends every event to a file as one JSON object per line. Queries read the
+// whole file, which suits an audit log that is mostly written and only now and then searched.
+type AuditRepository struct {
+	mu   sync.RWMutex
+	path string
+}
+
+func NewAuditRepository(path string) ports.AuditRepositoryInterface {
+	return &AuditRepository{path: path}
+}
+
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *AuditRepository) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	file, err := os.Open(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &domain.AuditPage{Events: []domain.AuditEvent{}, Limit: query.Limit, Offset: query.Offset}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var matched []domain.AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var event domain.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, err
+		}
+		if query.Matches(event) {
+			matched = append(matched, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The file is in the order events were recorded, pages start from its end
+	page := &domain.AuditPage{Events: []domain.AuditEvent{}, Total: int64(len(matched)), Limit: query.Limit, Offset: query.Offset}
+	for i := len(matched) - 1 - query.Offset; i >= 0 && (query.Limit <= 0 || len(page.Events) < query.Limit); i-- {
+		page.Events = append(page.Events, matched[i])
+	}
+	return page, nil
+}
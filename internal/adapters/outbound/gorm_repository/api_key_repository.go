@@ -0,0 +1,151 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+type apiKeyRecord struct {
+	ID      string `gorm:"primaryKey"`
+	Name    string
+	Prefix  string
+	KeyHash string
+	// Scopes are space separated, like an OAuth scope parameter
+	Scopes    string
+	CreatedBy string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
+
+func (apiKeyRecord) TableName() string { return "api_keys" }
+
+type APIKeyRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewAPIKeyRepository(db *gorm.DB, timeout time.Duration) ports.APIKeyRepositoryInterface {
+	repository := &APIKeyRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create API key repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r APIKeyRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	return db.Create(toAPIKeyRecord(key)).Error
+}
+
+func (r APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record apiKeyRecord
+	if err := db.Where("prefix = ?", prefix).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	key := record.toDomain()
+	return &key, nil
+}
+
+func (r APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var records []apiKeyRecord
+	if err := db.Order("created_at ASC").Order("id ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]domain.APIKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, record.toDomain())
+	}
+	return keys, nil
+}
+
+func (r APIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var record apiKeyRecord
+		if err := tx.Where("id = ?", objectID.Hex()).Take(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrAPIKeyNotFound
+			}
+			return err
+		}
+		return tx.Model(&apiKeyRecord{}).Where("id = ? AND revoked_at IS NULL", record.ID).Update("revoked_at", revokedAt).Error
+	})
+}
+
+func toAPIKeyRecord(key *domain.APIKey) *apiKeyRecord {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	record := &apiKeyRecord{
+		ID:        key.ID.Hex(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		KeyHash:   key.KeyHash,
+		Scopes:    strings.Join(scopes, " "),
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+	}
+	if !key.CreatedBy.IsZero() {
+		record.CreatedBy = key.CreatedBy.Hex()
+	}
+	return record
+}
+
+func (r apiKeyRecord) toDomain() domain.APIKey {
+	id, _ := primitive.ObjectIDFromHex(r.ID)
+	createdBy, _ := primitive.ObjectIDFromHex(r.CreatedBy)
+	scopes := []domain.Permission{}
+	for _, scope := range strings.Fields(r.Scopes) {
+		scopes = append(scopes, domain.Permission(scope))
+	}
+	return domain.APIKey{
+		ID:        id,
+		Name:      r.Name,
+		Prefix:    r.Prefix,
+		KeyHash:   r.KeyHash,
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: r.CreatedAt,
+		ExpiresAt: r.ExpiresAt,
+		RevokedAt: r.RevokedAt,
+	}
+}
@@ -0,0 +1,28 @@
+package gorm_repository
+
+import (
+	"context"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// Open connects to a SQLite database through the pure-Go driver, so no cgo toolchain is needed.
+// TranslateError is required for the adapters to see gorm.ErrDuplicatedKey.
+func Open(dsn string) (*gorm.DB, error) {
+	return gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Warn),
+	})
+}
+
+// session binds db to ctx and the per-operation deadline; call cancel once the operation is done.
+func session(ctx context.Context, db *gorm.DB, timeout time.Duration) (*gorm.DB, context.CancelFunc) {
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return db.WithContext(ctx), cancel
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return db.WithContext(ctx), cancel
+}
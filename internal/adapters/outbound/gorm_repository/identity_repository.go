@@ -0,0 +1,102 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type identityRecord struct {
+	ID        string `gorm:"primaryKey"`
+	UserID    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+func (identityRecord) TableName() string { return "identities" }
+
+type IdentityRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewIdentityRepository(db *gorm.DB, timeout time.Duration) ports.IdentityRepositoryInterface {
+	repository := &IdentityRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create identity repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r IdentityRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r IdentityRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	if identity.ID.IsZero() {
+		identity.ID = primitive.NewObjectID()
+	}
+	err := db.Create(&identityRecord{
+		ID:        identity.ID.Hex(),
+		UserID:    identity.UserID.Hex(),
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrIdentityLinked
+	}
+	return err
+}
+
+func (r IdentityRepository) GetIdentity(ctx context.Context, provider string, subject string) (*domain.Identity, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record identityRecord
+	if err := db.Where("provider = ? AND subject = ?", provider, subject).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	id, _ := primitive.ObjectIDFromHex(record.ID)
+	userID, _ := primitive.ObjectIDFromHex(record.UserID)
+	return &domain.Identity{
+		ID:        id,
+		UserID:    userID,
+		Provider:  record.Provider,
+		Subject:   record.Subject,
+		Email:     record.Email,
+		CreatedAt: record.CreatedAt,
+	}, nil
+}
+
+func (r IdentityRepository) DeleteIdentity(ctx context.Context, provider string, subject string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("provider = ? AND subject = ?", provider, subject).Delete(&identityRecord{}).Error
+}
+
+func (r IdentityRepository) DeleteUserIdentities(ctx context.Context, userID string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("user_id = ?", userID).Delete(&identityRecord{}).Error
+}
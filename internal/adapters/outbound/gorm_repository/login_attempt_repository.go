@@ -0,0 +1,115 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+type loginAttemptRecord struct {
+	ID            string `gorm:"primaryKey"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	ExpiresAt     time.Time
+}
+
+func (loginAttemptRecord) TableName() string { return "login_attempts" }
+
+type LoginAttemptRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewLoginAttemptRepository(db *gorm.DB, timeout time.Duration) ports.LoginAttemptRepositoryInterface {
+	repository := &LoginAttemptRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create login attempt repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r LoginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record loginAttemptRecord
+	if err := db.Where("id = ? AND expires_at > ?", key, time.Now()).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &domain.LoginAttempts{Key: key}, nil
+		}
+		return nil, err
+	}
+	return record.toDomain(), nil
+}
+
+func (r LoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	if err := db.Where("expires_at < ?", at).Delete(&loginAttemptRecord{}).Error; err != nil {
+		return nil, err
+	}
+
+	// The counter is bumped inside the upsert itself, so concurrent failures are never lost
+	end := at.Add(window)
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("CASE WHEN login_attempts.last_failure_at >= ? THEN login_attempts.failures + 1 ELSE 1 END", at.Add(-window))},
+			{Column: clause.Column{Name: "last_failure_at"}, Value: at},
+			{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("CASE WHEN login_attempts.expires_at > ? THEN login_attempts.expires_at ELSE ? END", end, end)},
+		},
+	}).Create(&loginAttemptRecord{ID: key, Failures: 1, LastFailureAt: at, ExpiresAt: end}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var record loginAttemptRecord
+	if err := db.Where("id = ?", key).Take(&record).Error; err != nil {
+		return nil, err
+	}
+	return record.toDomain(), nil
+}
+
+func (r LoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "locked_until"}, Value: until},
+			{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("CASE WHEN login_attempts.expires_at > ? THEN login_attempts.expires_at ELSE ? END", until, until)},
+		},
+	}).Create(&loginAttemptRecord{ID: key, LastFailureAt: time.Now(), LockedUntil: &until, ExpiresAt: until}).Error
+}
+
+func (r LoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("id = ?", key).Delete(&loginAttemptRecord{}).Error
+}
+
+func (r loginAttemptRecord) toDomain() *domain.LoginAttempts {
+	return &domain.LoginAttempts{
+		Key:           r.ID,
+		Failures:      r.Failures,
+		LastFailureAt: r.LastFailureAt,
+		LockedUntil:   r.LockedUntil,
+	}
+}
@@ -0,0 +1,137 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+type mfaRecord struct {
+	UserID        string `gorm:"primaryKey"`
+	Secret        string
+	LastUsedStep  int64
+	EnabledAt     *time.Time
+	CreatedAt     time.Time
+	RecoveryCodes []mfaRecoveryCodeRecord `gorm:"foreignKey:UserID"`
+}
+
+func (mfaRecord) TableName() string { return "mfa_settings" }
+
+// mfaRecoveryCodeRecord is one row per code, so using one is a single conditional delete.
+type mfaRecoveryCodeRecord struct {
+	UserID   string `gorm:"primaryKey"`
+	CodeHash string `gorm:"primaryKey"`
+}
+
+func (mfaRecoveryCodeRecord) TableName() string { return "mfa_recovery_codes" }
+
+type MFARepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewMFARepository(db *gorm.DB, timeout time.Duration) ports.MFARepositoryInterface {
+	repository := &MFARepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create MFA repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r MFARepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r MFARepository) SaveMFA(ctx context.Context, mfa *domain.MFA) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	userID := mfa.UserID.Hex()
+	return db.Transaction(func(tx *gorm.DB) error {
+		record := mfaRecord{
+			UserID:       userID,
+			Secret:       mfa.Secret,
+			LastUsedStep: mfa.LastUsedStep,
+			EnabledAt:    mfa.EnabledAt,
+			CreatedAt:    mfa.CreatedAt,
+		}
+		if err := tx.Omit("RecoveryCodes").Clauses(clause.OnConflict{UpdateAll: true}).Create(&record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&mfaRecoveryCodeRecord{}).Error; err != nil {
+			return err
+		}
+		if len(mfa.RecoveryCodes) == 0 {
+			return nil
+		}
+		codes := make([]mfaRecoveryCodeRecord, 0, len(mfa.RecoveryCodes))
+		for _, codeHash := range mfa.RecoveryCodes {
+			codes = append(codes, mfaRecoveryCodeRecord{UserID: userID, CodeHash: codeHash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r MFARepository) GetMFA(ctx context.Context, userID string) (*domain.MFA, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record mfaRecord
+	if err := db.Preload("RecoveryCodes").Where("user_id = ?", userID).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	objectID, _ := primitive.ObjectIDFromHex(record.UserID)
+	mfa := &domain.MFA{
+		UserID:        objectID,
+		Secret:        record.Secret,
+		RecoveryCodes: make([]string, 0, len(record.RecoveryCodes)),
+		LastUsedStep:  record.LastUsedStep,
+		EnabledAt:     record.EnabledAt,
+		CreatedAt:     record.CreatedAt,
+	}
+	for _, code := range record.RecoveryCodes {
+		mfa.RecoveryCodes = append(mfa.RecoveryCodes, code.CodeHash)
+	}
+	return mfa, nil
+}
+
+func (r MFARepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	result := db.Model(&mfaRecord{}).Where("user_id = ? AND last_used_step < ?", userID, step).Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r MFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	result := db.Where("user_id = ? AND code_hash = ?", userID, codeHash).Delete(&mfaRecoveryCodeRecord{})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r MFARepository) DeleteMFA(ctx context.Context, userID string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&mfaRecoveryCodeRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&mfaRecord{}).Error
+	})
+}
@@ -0,0 +1,275 @@
+package gorm_repository
+
+import (
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Each migration snapshots the table shape it creates, so later changes to the
+// live records never rewrite what an already applied version did.
+type usersV1 struct {
+	ID        string    `gorm:"primaryKey;size:24"`
+	Email     string    `gorm:"size:255;not null;uniqueIndex:idx_users_email"`
+	Name      string    `gorm:"size:255;not null"`
+	Password  string    `gorm:"size:255;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (usersV1) TableName() string { return "users" }
+
+type refreshTokensV2 struct {
+	ID        string    `gorm:"primaryKey;size:24"`
+	UserID    string    `gorm:"size:24;not null;index:idx_refresh_tokens_user_id"`
+	FamilyID  string    `gorm:"size:24;not null;index:idx_refresh_tokens_family_id"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex:idx_refresh_tokens_token_hash"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_refresh_tokens_expires_at"`
+	CreatedAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (refreshTokensV2) TableName() string { return "refresh_tokens" }
+
+type userRolesV3 struct {
+	UserID string `gorm:"primaryKey;size:24"`
+	Role   string `gorm:"primaryKey;size:32"`
+}
+
+func (userRolesV3) TableName() string { return "user_roles" }
+
+type passwordResetTokensV4 struct {
+	ID        string    `gorm:"primaryKey;size:24"`
+	UserID    string    `gorm:"size:24;not null;index:idx_password_reset_tokens_user_id"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex:idx_password_reset_tokens_token_hash"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_password_reset_tokens_expires_at"`
+	CreatedAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+func (passwordResetTokensV4) TableName() string { return "password_reset_tokens" }
+
+type usersV5 struct {
+	EmailVerified bool `gorm:"not null;default:false"`
+}
+
+func (usersV5) TableName() string { return "users" }
+
+type loginAttemptsV6 struct {
+	ID            string    `gorm:"primaryKey;size:320"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+	ExpiresAt     time.Time `gorm:"not null;index:idx_login_attempts_expires_at"`
+}
+
+func (loginAttemptsV6) TableName() string { return "login_attempts" }
+
+type apiKeysV7 struct {
+	ID        string    `gorm:"primaryKey;size:24"`
+	Name      string    `gorm:"size:255;not null"`
+	Prefix    string    `gorm:"size:32;not null;uniqueIndex:idx_api_keys_prefix"`
+	KeyHash   string    `gorm:"size:64;not null"`
+	Scopes    string    `gorm:"size:1024;not null"`
+	CreatedBy string    `gorm:"size:24"`
+	CreatedAt time.Time `gorm:"not null"`
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
+
+func (apiKeysV7) TableName() string { return "api_keys" }
+
+type identitiesV8 struct {
+	ID        string    `gorm:"primaryKey;size:24"`
+	UserID    string    `gorm:"size:24;not null;index:idx_identities_user_id"`
+	Provider  string    `gorm:"size:64;not null;uniqueIndex:idx_identities_provider_subject"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_identities_provider_subject"`
+	Email     string    `gorm:"size:255"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (identitiesV8) TableName() string { return "identities" }
+
+type mfaSettingsV9 struct {
+	UserID       string `gorm:"primaryKey;size:24"`
+	Secret       string `gorm:"size:64;not null"`
+	LastUsedStep int64  `gorm:"not null;default:0"`
+	EnabledAt    *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (mfaSettingsV9) TableName() string { return "mfa_settings" }
+
+type mfaRecoveryCodesV9 struct {
+	UserID   string `gorm:"primaryKey;size:24"`
+	CodeHash string `gorm:"primaryKey;size:64"`
+}
+
+func (mfaRecoveryCodesV9) TableName() string { return "mfa_recovery_codes" }
+
+type sessionsV10 struct {
+	ID         string    `gorm:"primaryKey;size:64"`
+	UserID     string    `gorm:"size:24;not null;index:idx_sessions_user_id"`
+	UserAgent  string    `gorm:"size:512"`
+	IP         string    `gorm:"size:64"`
+	TokenID    string    `gorm:"size:64"`
+	LastSeenAt time.Time `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index:idx_sessions_expires_at"`
+	RevokedAt  *time.Time
+}
+
+func (sessionsV10) TableName() string { return "sessions" }
+
+type usersV11 struct {
+	DeletedAt *time.Time `gorm:"index:idx_users_deleted_at"`
+}
+
+func (usersV11) TableName() string { return "users" }
+
+type usersV12 struct {
+	Version int64 `gorm:"not null;default:0"`
+}
+
+func (usersV12) TableName() string { return "users" }
+
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create_users",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&usersV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_refresh_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&refreshTokensV2{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_user_roles",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&userRolesV3{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_password_reset_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&passwordResetTokensV4{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_users_email_verified",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&usersV5{}, "EmailVerified")
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_login_attempts",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&loginAttemptsV6{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_api_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiKeysV7{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&identitiesV8{})
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_mfa",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&mfaSettingsV9{}, &mfaRecoveryCodesV9{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "create_sessions",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&sessionsV10{})
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_users_deleted_at",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&usersV11{}, "DeletedAt"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateIndex(&usersV11{}, "idx_users_deleted_at"); err != nil {
+				return err
+			}
+			// Deleted users give their email up, only the others have to keep it unique
+			if err := tx.Migrator().DropIndex(&usersV1{}, "idx_users_email"); err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL").Error
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_users_version",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&usersV12{}, "Version")
+		},
+	},
+}
+
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	var applied []schemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return err
+	}
+	done := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type passwordResetTokenRecord struct {
+	ID        string `gorm:"primaryKey"`
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+func (passwordResetTokenRecord) TableName() string { return "password_reset_tokens" }
+
+type PasswordResetTokenRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB, timeout time.Duration) ports.PasswordResetTokenRepositoryInterface {
+	repository := &PasswordResetTokenRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create password reset token repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r PasswordResetTokenRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r PasswordResetTokenRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&passwordResetTokenRecord{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&passwordResetTokenRecord{
+		ID:        token.ID.Hex(),
+		UserID:    token.UserID.Hex(),
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+		UsedAt:    token.UsedAt,
+	}).Error
+}
+
+func (r PasswordResetTokenRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record passwordResetTokenRecord
+	if err := db.Where("token_hash = ?", tokenHash).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	id, _ := primitive.ObjectIDFromHex(record.ID)
+	userID, _ := primitive.ObjectIDFromHex(record.UserID)
+	return &domain.PasswordResetToken{
+		ID:        id,
+		UserID:    userID,
+		TokenHash: record.TokenHash,
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+		UsedAt:    record.UsedAt,
+	}, nil
+}
+
+func (r PasswordResetTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	result := db.Model(&passwordResetTokenRecord{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r PasswordResetTokenRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("user_id = ?", userID).Delete(&passwordResetTokenRecord{}).Error
+}
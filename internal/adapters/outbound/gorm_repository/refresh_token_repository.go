@@ -0,0 +1,132 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type refreshTokenRecord struct {
+	ID        string `gorm:"primaryKey"`
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (refreshTokenRecord) TableName() string { return "refresh_tokens" }
+
+type RefreshTokenRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewRefreshTokenRepository(db *gorm.DB, timeout time.Duration) ports.RefreshTokenRepositoryInterface {
+	repository := &RefreshTokenRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create refresh token repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r RefreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+
+	// Expired tokens are swept on write, there is no TTL index like on Mongo
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&refreshTokenRecord{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&refreshTokenRecord{
+		ID:        token.ID.Hex(),
+		UserID:    token.UserID.Hex(),
+		FamilyID:  token.FamilyID,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+		UsedAt:    token.UsedAt,
+		RevokedAt: token.RevokedAt,
+	}).Error
+}
+
+func (r RefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record refreshTokenRecord
+	if err := db.Where("token_hash = ?", tokenHash).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	id, _ := primitive.ObjectIDFromHex(record.ID)
+	userID, _ := primitive.ObjectIDFromHex(record.UserID)
+	return &domain.RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  record.FamilyID,
+		TokenHash: record.TokenHash,
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+		UsedAt:    record.UsedAt,
+		RevokedAt: record.RevokedAt,
+	}, nil
+}
+
+func (r RefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	result := db.Model(&refreshTokenRecord{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r RefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Model(&refreshTokenRecord{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r RefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Model(&refreshTokenRecord{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r RefreshTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("user_id = ?", userID).Delete(&refreshTokenRecord{}).Error
+}
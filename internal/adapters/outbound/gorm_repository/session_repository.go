@@ -0,0 +1,170 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type sessionRecord struct {
+	ID         string `gorm:"primaryKey"`
+	UserID     string
+	UserAgent  string
+	IP         string
+	TokenID    string
+	LastSeenAt time.Time
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+func (sessionRecord) TableName() string { return "sessions" }
+
+type SessionRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewSessionRepository(db *gorm.DB, timeout time.Duration) ports.SessionRepositoryInterface {
+	repository := &SessionRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create session repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r SessionRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (r SessionRepository) CreateSession(ctx context.Context, s *domain.Session) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Create(&sessionRecord{
+		ID:         s.ID,
+		UserID:     s.UserID.Hex(),
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		TokenID:    s.TokenID,
+		LastSeenAt: s.LastSeenAt,
+		CreatedAt:  s.CreatedAt,
+		ExpiresAt:  s.ExpiresAt,
+		RevokedAt:  s.RevokedAt,
+	}).Error
+}
+
+func (r SessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var record sessionRecord
+	if err := db.Where("id = ?", id).Take(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	s := record.toDomain()
+	return &s, nil
+}
+
+func (r SessionRepository) ListUserSessions(ctx context.Context, userID string, now time.Time) ([]domain.Session, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var records []sessionRecord
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]domain.Session, 0, len(records))
+	for _, record := range records {
+		sessions = append(sessions, record.toDomain())
+	}
+	return sessions, nil
+}
+
+func (r SessionRepository) TouchSession(ctx context.Context, id string, tokenID string, lastSeenAt time.Time, expiresAt time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	result := db.Model(&sessionRecord{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"token_id":     tokenID,
+		"last_seen_at": lastSeenAt,
+		"expires_at":   expiresAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r SessionRepository) MarkSessionSeen(ctx context.Context, id string, seenAt time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Model(&sessionRecord{}).Where("id = ? AND last_seen_at < ?", id, seenAt).Update("last_seen_at", seenAt).Error
+}
+
+func (r SessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Model(&sessionRecord{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+func (r SessionRepository) RevokeUserSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) ([]string, error) {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	var revoked []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&sessionRecord{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, exceptID, revokedAt).
+			Pluck("id", &revoked).Error
+		if err != nil || len(revoked) == 0 {
+			return err
+		}
+		return tx.Model(&sessionRecord{}).Where("id IN ?", revoked).Update("revoked_at", revokedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+func (r SessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	db, cancel := session(ctx, r.db, r.timeout)
+	defer cancel()
+
+	return db.Where("user_id = ?", userID).Delete(&sessionRecord{}).Error
+}
+
+func (r sessionRecord) toDomain() domain.Session {
+	userID, _ := primitive.ObjectIDFromHex(r.UserID)
+	return domain.Session{
+		ID:         r.ID,
+		UserID:     userID,
+		UserAgent:  r.UserAgent,
+		IP:         r.IP,
+		TokenID:    r.TokenID,
+		LastSeenAt: r.LastSeenAt,
+		CreatedAt:  r.CreatedAt,
+		ExpiresAt:  r.ExpiresAt,
+		RevokedAt:  r.RevokedAt,
+	}
+}
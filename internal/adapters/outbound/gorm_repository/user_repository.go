@@ -0,0 +1,460 @@
+package gorm_repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"strings"
+	"time"
+)
+
+type userRecord struct {
+	ID            string `gorm:"primaryKey"`
+	Email         string
+	Name          string
+	Password      string
+	EmailVerified bool
+	CreatedAt     time.Time
+	Version       int64
+	// DeletedAt makes gorm skip deleted users in every query but the Unscoped ones.
+	DeletedAt gorm.DeletedAt
+	Roles     []userRoleRecord `gorm:"foreignKey:UserID"`
+}
+
+func (userRecord) TableName() string { return "users" }
+
+type userRoleRecord struct {
+	UserID string `gorm:"primaryKey"`
+	Role   string `gorm:"primaryKey"`
+}
+
+func (userRoleRecord) TableName() string { return "user_roles" }
+
+var sortColumns = map[string]string{
+	domain.SortByName:      "name",
+	domain.SortByEmail:     "email",
+	domain.SortByCreatedAt: "created_at",
+}
+
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+type UserRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// NewUserRepository bounds every operation by timeout on top of the caller's context; zero disables the deadline.
+func NewUserRepository(db *gorm.DB, timeout time.Duration) ports.UserRepositoryInterface {
+	repository := &UserRepository{db: db, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create user repository: %v", err)
+	}
+
+	return repository
+}
+
+// EnsureIndexes runs the pending schema migrations, which own the unique email index.
+func (u UserRepository) EnsureIndexes(ctx context.Context) error {
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	return Migrate(db)
+}
+
+func (u UserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Password hashing failed: ", err)
+		return err
+	}
+	user.Password = string(hashedPassword)
+	user.CreatedAt = time.Now()
+	user.Version = 1
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	err = db.Create(toUserRecord(user)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("%w: %s", domain.ErrEmailAlreadyExists, user.Email)
+		}
+		log.Println("SQL insert error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u UserRepository) GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	tx := db.Model(&userRecord{})
+	if query.EmailPrefix != "" {
+		tx = tx.Where("email LIKE ? ESCAPE '\\'", escapeLike(query.EmailPrefix)+"%")
+	}
+	if query.NameContains != "" {
+		tx = tx.Where("LOWER(name) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(query.NameContains))+"%")
+	}
+	if query.CreatedAfter != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedBefore)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// id breaks ties so pages stay stable when the sort key repeats
+	direction := "ASC"
+	if query.SortDesc {
+		direction = "DESC"
+	}
+	column := sortColumns[query.SortBy]
+	if column == "" {
+		column = sortColumns[domain.SortByCreatedAt]
+	}
+	tx = tx.Omit("password").Preload("Roles").Order(column + " " + direction).Order("id " + direction).Offset(query.Offset)
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	var records []userRecord
+	if err := tx.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0, len(records))
+	for _, record := range records {
+		users = append(users, record.toDomain())
+	}
+	return &domain.UserPage{Users: users, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+func (u UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := u.GetUserLoginByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+func (u UserRepository) GetUserLoginByEmail(ctx context.Context, email string) (*domain.User, error) {
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	var record userRecord
+	if err := db.Preload("Roles").Where("email = ?", email).Take(&record).Error; err != nil {
+		return nil, notFound(err)
+	}
+	user := record.toDomain()
+	return &user, nil
+}
+
+func (u UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	return findUser(db, objectID.Hex())
+}
+
+func (u UserRepository) UpdateUserByID(ctx context.Context, id string, version int64, update domain.UserUpdate) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	if update.Name != nil {
+		columns["name"] = *update.Name
+	}
+	if update.Email != nil {
+		columns["email"] = *update.Email
+	}
+	if update.EmailVerified != nil {
+		columns["email_verified"] = *update.EmailVerified
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	// Reading back in the same transaction returns exactly what was written
+	var user *domain.User
+	err = db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&userRecord{}).Where("id = ?", objectID.Hex())
+		if version != domain.AnyVersion {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(columns)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return domain.ErrEmailAlreadyExists
+			}
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Either the user is gone or someone else wrote first
+			if _, err := findUser(tx, objectID.Hex()); err != nil {
+				return err
+			}
+			return domain.ErrVersionMismatch
+		}
+		var err error
+		user, err = findUser(tx, objectID.Hex())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u UserRepository) UpdateUserPassword(ctx context.Context, id string, password string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Password hashing failed: ", err)
+		return err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	result := db.Model(&userRecord{}).Where("id = ?", objectID.Hex()).Updates(map[string]interface{}{"password": string(hashedPassword), "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) MarkUserEmailVerified(ctx context.Context, id string, email string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	result := db.Model(&userRecord{}).Where("id = ? AND email = ?", objectID.Hex(), email).Updates(map[string]interface{}{"email_verified": true, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) DeleteUserByID(ctx context.Context, id string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	// The roles stay, a restored user gets them back
+	result := db.Model(&userRecord{}).Where("id = ?", objectID.Hex()).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	result := db.Unscoped().Model(&userRecord{}).
+		Where("id = ? AND deleted_at IS NOT NULL", objectID.Hex()).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, domain.ErrEmailAlreadyExists
+		}
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return u.GetUserByID(ctx, id)
+}
+
+func (u UserRepository) GetDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error) {
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	var records []userRecord
+	err := db.Unscoped().Omit("password").Preload("Roles").Where("deleted_at < ?", deletedBefore).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	users := make([]domain.User, 0, len(records))
+	for _, record := range records {
+		users = append(users, record.toDomain())
+	}
+	return users, nil
+}
+
+func (u UserRepository) PurgeUserByID(ctx context.Context, id string, deletedBefore time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at < ?", objectID.Hex(), deletedBefore).Delete(&userRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		return tx.Where("user_id = ?", objectID.Hex()).Delete(&userRoleRecord{}).Error
+	})
+}
+
+func (u UserRepository) AddUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(ctx, id, func(tx *gorm.DB, userID string) error {
+		record := userRoleRecord{UserID: userID, Role: role}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+	})
+}
+
+func (u UserRepository) RemoveUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(ctx, id, func(tx *gorm.DB, userID string) error {
+		return tx.Where("user_id = ? AND role = ?", userID, role).Delete(&userRoleRecord{}).Error
+	})
+}
+
+// updateRoles bumps the user's version together with the role change, the roles live in their own table.
+func (u UserRepository) updateRoles(ctx context.Context, id string, update func(tx *gorm.DB, userID string) error) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	db, cancel := session(ctx, u.db, u.timeout)
+	defer cancel()
+
+	var user *domain.User
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&userRecord{}).Where("id = ?", objectID.Hex()).Update("version", gorm.Expr("version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		if err := update(tx, objectID.Hex()); err != nil {
+			return err
+		}
+		var err error
+		user, err = findUser(tx, objectID.Hex())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func findUser(db *gorm.DB, id string) (*domain.User, error) {
+	var record userRecord
+	if err := db.Omit("password").Preload("Roles").Where("id = ?", id).Take(&record).Error; err != nil {
+		return nil, notFound(err)
+	}
+	user := record.toDomain()
+	return &user, nil
+}
+
+func toUserRecord(user *domain.User) *userRecord {
+	record := &userRecord{
+		ID:            user.ID.Hex(),
+		Email:         user.Email,
+		Name:          user.Name,
+		Password:      user.Password,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt,
+		Version:       user.Version,
+	}
+	for _, role := range user.Roles {
+		record.Roles = append(record.Roles, userRoleRecord{UserID: record.ID, Role: role})
+	}
+	return record
+}
+
+func (r userRecord) toDomain() domain.User {
+	objectID, _ := primitive.ObjectIDFromHex(r.ID)
+	var roles []string
+	for _, role := range r.Roles {
+		roles = append(roles, role.Role)
+	}
+	var deletedAt *time.Time
+	if r.DeletedAt.Valid {
+		deletedAt = &r.DeletedAt.Time
+	}
+	return domain.User{
+		ID:            objectID,
+		Email:         r.Email,
+		Name:          r.Name,
+		Password:      r.Password,
+		Roles:         roles,
+		EmailVerified: r.EmailVerified,
+		CreatedAt:     r.CreatedAt,
+		Version:       r.Version,
+		DeletedAt:     deletedAt,
+	}
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrUserNotFound
+	}
+	return err
+}
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
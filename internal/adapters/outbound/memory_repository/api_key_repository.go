@@ -0,0 +1,90 @@
+package memory_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type APIKeyRepository struct {
+	mu       sync.Mutex
+	keys     map[primitive.ObjectID]domain.APIKey
+	prefixes map[string]primitive.ObjectID
+}
+
+func NewAPIKeyRepository() ports.APIKeyRepositoryInterface {
+	return &APIKeyRepository{
+		keys:     make(map[primitive.ObjectID]domain.APIKey),
+		prefixes: make(map[string]primitive.ObjectID),
+	}
+}
+
+func (r *APIKeyRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	r.keys[key.ID] = *key
+	r.prefixes[key.Prefix] = key.ID
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.prefixes[prefix]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	key := r.keys[id]
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]domain.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b domain.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.Hex(), b.ID.Hex())
+	})
+	return keys, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[objectID]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+		r.keys[objectID] = key
+	}
+	return nil
+}
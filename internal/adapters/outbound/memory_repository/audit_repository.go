@@ -0,0 +1,59 @@
+package memory_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"maps"
+	"sync"
+)
+
+type AuditRepository struct {
+	mu     sync.RWMutex
+	events []domain.AuditEvent
+}
+
+func NewAuditRepository() ports.AuditRepositoryInterface {
+	return &AuditRepository{}
+}
+
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *AuditRepository) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	stored := *event
+	stored.Changes = maps.Clone(event.Changes)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, stored)
+	return nil
+}
+
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return pageAuditEvents(r.events, query), nil
+}
+
+// pageAuditEvents walks events from the newest, they are kept in the order they were recorded.
+func pageAuditEvents(events []domain.AuditEvent, query domain.AuditQuery) *domain.AuditPage {
+	page := &domain.AuditPage{Events: []domain.AuditEvent{}, Limit: query.Limit, Offset: query.Offset}
+	for i := len(events) - 1; i >= 0; i-- {
+		if !query.Matches(events[i]) {
+			continue
+		}
+		if page.Total >= int64(query.Offset) && (query.Limit <= 0 || len(page.Events) < query.Limit) {
+			page.Events = append(page.Events, events[i])
+		}
+		page.Total++
+	}
+	return page
+}
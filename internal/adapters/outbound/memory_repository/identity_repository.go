@@ -0,0 +1,73 @@
+package memory_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"sync"
+)
+
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+type IdentityRepository struct {
+	mu         sync.Mutex
+	identities map[identityKey]domain.Identity
+}
+
+func NewIdentityRepository() ports.IdentityRepositoryInterface {
+	return &IdentityRepository{identities: make(map[identityKey]domain.Identity)}
+}
+
+func (r *IdentityRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identityKey{provider: identity.Provider, subject: identity.Subject}
+	if _, exists := r.identities[key]; exists {
+		return domain.ErrIdentityLinked
+	}
+	if identity.ID.IsZero() {
+		identity.ID = primitive.NewObjectID()
+	}
+	r.identities[key] = *identity
+	return nil
+}
+
+func (r *IdentityRepository) GetIdentity(ctx context.Context, provider string, subject string) (*domain.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity, ok := r.identities[identityKey{provider: provider, subject: subject}]
+	if !ok {
+		return nil, domain.ErrIdentityNotFound
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) DeleteIdentity(ctx context.Context, provider string, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.identities, identityKey{provider: provider, subject: subject})
+	return nil
+}
+
+func (r *IdentityRepository) DeleteUserIdentities(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, identity := range r.identities {
+		if identity.UserID.Hex() == userID {
+			delete(r.identities, key)
+		}
+	}
+	return nil
+}
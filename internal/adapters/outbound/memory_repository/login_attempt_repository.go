@@ -0,0 +1,90 @@
+package memory_repository
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"sync"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempts
+	// expires drops idle keys so unknown emails cannot grow the map forever
+	expires map[string]time.Time
+}
+
+func NewLoginAttemptRepository() ports.LoginAttemptRepositoryInterface {
+	return &LoginAttemptRepository{
+		attempts: make(map[string]domain.LoginAttempts),
+		expires:  make(map[string]time.Time),
+	}
+}
+
+func (r *LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *LoginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok || time.Now().After(r.expires[key]) {
+		return &domain.LoginAttempts{Key: key}, nil
+	}
+	return &attempts, nil
+}
+
+func (r *LoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, expiresAt := range r.expires {
+		if at.After(expiresAt) {
+			delete(r.attempts, k)
+			delete(r.expires, k)
+		}
+	}
+
+	attempts, ok := r.attempts[key]
+	if !ok || attempts.LastFailureAt.Before(at.Add(-window)) {
+		attempts = domain.LoginAttempts{Key: key, LockedUntil: attempts.LockedUntil}
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = at
+	r.attempts[key] = attempts
+	r.expires[key] = laterOf(r.expires[key], at.Add(window))
+	return &attempts, nil
+}
+
+func (r *LoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		attempts = domain.LoginAttempts{Key: key}
+	}
+	attempts.LockedUntil = &until
+	r.attempts[key] = attempts
+	r.expires[key] = laterOf(r.expires[key], until)
+	return nil
+}
+
+func (r *LoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	delete(r.expires, key)
+	return nil
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+package memory_repository
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"slices"
+	"sync"
+)
+
+type MFARepository struct {
+	mu       sync.Mutex
+	settings map[string]domain.MFA
+}
+
+func NewMFARepository() ports.MFARepositoryInterface {
+	return &MFARepository{settings: make(map[string]domain.MFA)}
+}
+
+func (r *MFARepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *MFARepository) SaveMFA(ctx context.Context, mfa *domain.MFA) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *mfa
+	stored.RecoveryCodes = slices.Clone(mfa.RecoveryCodes)
+	r.settings[mfa.UserID.Hex()] = stored
+	return nil
+}
+
+func (r *MFARepository) GetMFA(ctx context.Context, userID string) (*domain.MFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.settings[userID]
+	if !ok {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	mfa.RecoveryCodes = slices.Clone(mfa.RecoveryCodes)
+	return &mfa, nil
+}
+
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.settings[userID]
+	if !ok || step <= mfa.LastUsedStep {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	r.settings[userID] = mfa
+	return true, nil
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.settings[userID]
+	if !ok {
+		return false, nil
+	}
+	i := slices.Index(mfa.RecoveryCodes, codeHash)
+	if i < 0 {
+		return false, nil
+	}
+	mfa.RecoveryCodes = slices.Delete(slices.Clone(mfa.RecoveryCodes), i, i+1)
+	r.settings[userID] = mfa
+	return true, nil
+}
+
+func (r *MFARepository) DeleteMFA(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.settings, userID)
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"sync"
+	"time"
+)
+
+type PasswordResetTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]domain.PasswordResetToken
+	hashes map[string]primitive.ObjectID
+}
+
+func NewPasswordResetTokenRepository() ports.PasswordResetTokenRepositoryInterface {
+	return &PasswordResetTokenRepository{
+		tokens: make(map[primitive.ObjectID]domain.PasswordResetToken),
+		hashes: make(map[string]primitive.ObjectID),
+	}
+}
+
+func (r *PasswordResetTokenRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *PasswordResetTokenRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range r.tokens {
+		if now.After(existing.ExpiresAt) {
+			delete(r.hashes, existing.TokenHash)
+			delete(r.tokens, id)
+		}
+	}
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	r.tokens[token.ID] = *token
+	r.hashes[token.TokenHash] = token.ID
+	return nil
+}
+
+func (r *PasswordResetTokenRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.hashes[tokenHash]
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+	token := r.tokens[id]
+	return &token, nil
+}
+
+func (r *PasswordResetTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[objectID]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	r.tokens[objectID] = token
+	return true, nil
+}
+
+func (r *PasswordResetTokenRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID.Hex() == userID {
+			delete(r.hashes, token.TokenHash)
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
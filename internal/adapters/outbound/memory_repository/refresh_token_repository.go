@@ -0,0 +1,117 @@
+package memory_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"sync"
+	"time"
+)
+
+type RefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]domain.RefreshToken
+	hashes map[string]primitive.ObjectID
+}
+
+func NewRefreshTokenRepository() ports.RefreshTokenRepositoryInterface {
+	return &RefreshTokenRepository{
+		tokens: make(map[primitive.ObjectID]domain.RefreshToken),
+		hashes: make(map[string]primitive.ObjectID),
+	}
+}
+
+func (r *RefreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Expired tokens are dropped here, so the maps don't grow without bound
+	now := time.Now()
+	for id, existing := range r.tokens {
+		if now.After(existing.ExpiresAt) {
+			delete(r.hashes, existing.TokenHash)
+			delete(r.tokens, id)
+		}
+	}
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	r.tokens[token.ID] = *token
+	r.hashes[token.TokenHash] = token.ID
+	return nil
+}
+
+func (r *RefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.hashes[tokenHash]
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+	token := r.tokens[id]
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[objectID]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	r.tokens[objectID] = token
+	return true, nil
+}
+
+func (r *RefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID.Hex() == userID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID.Hex() == userID {
+			delete(r.hashes, token.TokenHash)
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package memory_repository
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"slices"
+	"sync"
+	"time"
+)
+
+type SessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]domain.Session
+}
+
+func NewSessionRepository() ports.SessionRepositoryInterface {
+	return &SessionRepository{sessions: make(map[string]domain.Session)}
+}
+
+func (r *SessionRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) ListUserSessions(ctx context.Context, userID string, now time.Time) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions := []domain.Session{}
+	for _, session := range r.sessions {
+		if session.UserID.Hex() == userID && session.IsActive(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b domain.Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, tokenID string, lastSeenAt time.Time, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return domain.ErrSessionNotFound
+	}
+	session.TokenID = tokenID
+	session.LastSeenAt = lastSeenAt
+	session.ExpiresAt = expiresAt
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *SessionRepository) MarkSessionSeen(ctx context.Context, id string, seenAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && seenAt.After(session.LastSeenAt) {
+		session.LastSeenAt = seenAt
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked []string
+	for id, session := range r.sessions {
+		if session.UserID.Hex() != userID || id == exceptID || !session.IsActive(revokedAt) {
+			continue
+		}
+		session.RevokedAt = &revokedAt
+		r.sessions[id] = session
+		revoked = append(revoked, id)
+	}
+	return revoked, nil
+}
+
+func (r *SessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.UserID.Hex() == userID {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory_repository
+
+import (
+	"context"
+	"golang-rest/internal/core/ports"
+	"sync"
+	"time"
+)
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+// TokenRevocationRepository only knows the revocations made on this instance.
+type TokenRevocationRepository struct {
+	mu       sync.Mutex
+	tokens   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[string]userRevocation
+}
+
+func NewTokenRevocationRepository() ports.TokenRevocationRepositoryInterface {
+	return &TokenRevocationRepository{
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		users:    make(map[string]userRevocation),
+	}
+}
+
+func (r *TokenRevocationRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeExpired(time.Now())
+	r.tokens[tokenID] = laterOf(r.tokens[tokenID], expiresAt)
+	return nil
+}
+
+func (r *TokenRevocationRepository) RevokeSession(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeExpired(time.Now())
+	r.sessions[sessionID] = laterOf(r.sessions[sessionID], expiresAt)
+	return nil
+}
+
+func (r *TokenRevocationRepository) RevokeUserAccessTokens(ctx context.Context, userID string, issuedBefore time.Time, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeExpired(time.Now())
+	current := r.users[userID]
+	r.users[userID] = userRevocation{
+		issuedBefore: laterOf(current.issuedBefore, issuedBefore),
+		expiresAt:    laterOf(current.expiresAt, expiresAt),
+	}
+	return nil
+}
+
+func (r *TokenRevocationRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string, sessionID string, userID string, issuedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := r.tokens[tokenID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if expiresAt, ok := r.sessions[sessionID]; ok && sessionID != "" && now.Before(expiresAt) {
+		return true, nil
+	}
+	if revocation, ok := r.users[userID]; ok && now.Before(revocation.expiresAt) && issuedAt.Before(revocation.issuedBefore) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// removeExpired runs on every write, so the maps only ever hold tokens that could still be presented.
+func (r *TokenRevocationRepository) removeExpired(now time.Time) {
+	for tokenID, expiresAt := range r.tokens {
+		if !now.Before(expiresAt) {
+			delete(r.tokens, tokenID)
+		}
+	}
+	for sessionID, expiresAt := range r.sessions {
+		if !now.Before(expiresAt) {
+			delete(r.sessions, sessionID)
+		}
+	}
+	for userID, revocation := range r.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(r.users, userID)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package memory_repository
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang.org/x/crypto/bcrypt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserRepository keeps deleted users in users until they are purged, emails only indexes the others.
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  map[primitive.ObjectID]domain.User
+	emails map[string]primitive.ObjectID
+}
+
+func NewUserRepository() ports.UserRepositoryInterface {
+	return &UserRepository{
+		users:  make(map[primitive.ObjectID]domain.User),
+		emails: make(map[string]primitive.ObjectID),
+	}
+}
+
+// EnsureIndexes is a no-op, the email index is maintained on every write.
+func (u *UserRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (u *UserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, exists := u.emails[user.Email]; exists {
+		return fmt.Errorf("%w: %s", domain.ErrEmailAlreadyExists, user.Email)
+	}
+
+	user.Password = string(hashedPassword)
+	user.CreatedAt = time.Now()
+	user.Version = 1
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	stored := *user
+	stored.Roles = slices.Clone(user.Roles)
+	u.users[user.ID] = stored
+	u.emails[user.Email] = user.ID
+	return nil
+}
+
+func (u *UserRepository) GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	users := make([]domain.User, 0, len(u.users))
+	for _, user := range u.users {
+		if user.DeletedAt != nil || !matchesQuery(user, query) {
+			continue
+		}
+		user.Password = ""
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if query.SortDesc {
+			i, j = j, i
+		}
+		if c := compareUsers(users[i], users[j], query.SortBy); c != 0 {
+			return c < 0
+		}
+		return users[i].ID.Hex() < users[j].ID.Hex()
+	})
+
+	total := len(users)
+	start := min(query.Offset, total)
+	end := total
+	if query.Limit > 0 {
+		end = min(start+query.Limit, total)
+	}
+	return &domain.UserPage{Users: users[start:end], Total: int64(total), Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := u.GetUserLoginByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+func (u *UserRepository) GetUserLoginByEmail(ctx context.Context, email string) (*domain.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	id, ok := u.emails[email]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user := u.users[id]
+	return &user, nil
+}
+
+func (u *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user.Password = ""
+	return &user, nil
+}
+
+func (u *UserRepository) UpdateUserByID(ctx context.Context, id string, version int64, update domain.UserUpdate) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	if version != domain.AnyVersion && user.Version != version {
+		return nil, domain.ErrVersionMismatch
+	}
+
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if update.Email != nil && *update.Email != user.Email {
+		if _, exists := u.emails[*update.Email]; exists {
+			return nil, fmt.Errorf("%w: %s", domain.ErrEmailAlreadyExists, *update.Email)
+		}
+		delete(u.emails, user.Email)
+		u.emails[*update.Email] = objectID
+		user.Email = *update.Email
+	}
+	if update.EmailVerified != nil {
+		user.EmailVerified = *update.EmailVerified
+	}
+	user.Version++
+
+	u.users[objectID] = user
+	user.Password = ""
+	return &user, nil
+}
+
+func (u *UserRepository) UpdateUserPassword(ctx context.Context, id string, password string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Password = string(hashedPassword)
+	user.Version++
+	u.users[objectID] = user
+	return nil
+}
+
+func (u *UserRepository) MarkUserEmailVerified(ctx context.Context, id string, email string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok || user.Email != email {
+		return domain.ErrUserNotFound
+	}
+	user.EmailVerified = true
+	user.Version++
+	u.users[objectID] = user
+	return nil
+}
+
+func (u *UserRepository) DeleteUserByID(ctx context.Context, id string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	now := time.Now()
+	user.DeletedAt = &now
+	user.Version++
+	u.users[objectID] = user
+	delete(u.emails, user.Email)
+	return nil
+}
+
+func (u *UserRepository) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.users[objectID]
+	if !ok || user.DeletedAt == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if _, exists := u.emails[user.Email]; exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrEmailAlreadyExists, user.Email)
+	}
+	user.DeletedAt = nil
+	user.Version++
+	u.users[objectID] = user
+	u.emails[user.Email] = objectID
+
+	user.Password = ""
+	return &user, nil
+}
+
+func (u *UserRepository) GetDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	users := []domain.User{}
+	for _, user := range u.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(deletedBefore) {
+			user.Password = ""
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (u *UserRepository) PurgeUserByID(ctx context.Context, id string, deletedBefore time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.users[objectID]
+	if !ok || user.DeletedAt == nil || !user.DeletedAt.Before(deletedBefore) {
+		return domain.ErrUserNotFound
+	}
+	delete(u.users, objectID)
+	return nil
+}
+
+func (u *UserRepository) activeUser(id primitive.ObjectID) (domain.User, bool) {
+	user, ok := u.users[id]
+	if !ok || user.DeletedAt != nil {
+		return domain.User{}, false
+	}
+	return user, true
+}
+
+func matchesQuery(user domain.User, query domain.UserQuery) bool {
+	if query.EmailPrefix != "" && !strings.HasPrefix(user.Email, query.EmailPrefix) {
+		return false
+	}
+	if query.NameContains != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(query.NameContains)) {
+		return false
+	}
+	if query.CreatedAfter != nil && user.CreatedAt.Before(*query.CreatedAfter) {
+		return false
+	}
+	if query.CreatedBefore != nil && !user.CreatedAt.Before(*query.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func compareUsers(a domain.User, b domain.User, sortBy string) int {
+	switch sortBy {
+	case domain.SortByName:
+		return strings.Compare(a.Name, b.Name)
+	case domain.SortByEmail:
+		return strings.Compare(a.Email, b.Email)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+func (u *UserRepository) AddUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(id, func(roles []string) []string {
+		if slices.Contains(roles, role) {
+			return roles
+		}
+		return append(roles, role)
+	})
+}
+
+func (u *UserRepository) RemoveUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(id, func(roles []string) []string {
+		return slices.DeleteFunc(roles, func(r string) bool { return r == role })
+	})
+}
+
+// updateRoles works on a copy, so slices already handed out to readers never change underneath them.
+func (u *UserRepository) updateRoles(id string, update func(roles []string) []string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.activeUser(objectID)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user.Roles = update(slices.Clone(user.Roles))
+	user.Version++
+	u.users[objectID] = user
+
+	user.Password = ""
+	return &user, nil
+}
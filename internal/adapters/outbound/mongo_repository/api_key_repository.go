@@ -0,0 +1,100 @@
+package mongo_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+type APIKeyRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewAPIKeyRepository(collection *mongo.Collection, timeout time.Duration) ports.APIKeyRepositoryInterface {
+	repository := &APIKeyRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create API key repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r APIKeyRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"prefix": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var key domain.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r APIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// $min only writes revoked_at when it is unset or later, so the first revocation wins
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$min": bson.M{"revoked_at": revokedAt}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package mongo_repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+type AuditRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewAuditRepository(collection *mongo.Collection, timeout time.Duration) ports.AuditRepositoryInterface {
+	repository := &AuditRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create audit repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r AuditRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (r AuditRepository) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+func (r AuditRepository) ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{}
+	for field, value := range map[string]string{
+		"action":       query.Action,
+		"outcome":      query.Outcome,
+		"actor_id":     query.ActorID,
+		"target_id":    query.TargetID,
+		"target_email": query.TargetEmail,
+	} {
+		if value != "" {
+			filter[field] = value
+		}
+	}
+	createdAt := bson.M{}
+	if query.From != nil {
+		createdAt["$gte"] = *query.From
+	}
+	if query.To != nil {
+		createdAt["$lt"] = *query.To
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// _id breaks ties between events of the same instant
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []domain.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return &domain.AuditPage{Events: events, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
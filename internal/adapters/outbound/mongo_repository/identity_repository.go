@@ -0,0 +1,89 @@
+package mongo_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+type IdentityRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewIdentityRepository(collection *mongo.Collection, timeout time.Duration) ports.IdentityRepositoryInterface {
+	repository := &IdentityRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create identity repository: %v", err)
+	}
+
+	return repository
+}
+
+func (r IdentityRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"user_id": 1}},
+	})
+	return err
+}
+
+func (r IdentityRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if identity.ID.IsZero() {
+		identity.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, identity)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrIdentityLinked
+	}
+	return err
+}
+
+func (r IdentityRepository) GetIdentity(ctx context.Context, provider string, subject string) (*domain.Identity, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var identity domain.Identity
+	err := r.collection.FindOne(ctx, bson.M{"provider": provider, "subject": subject}).Decode(&identity)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r IdentityRepository) DeleteIdentity(ctx context.Context, provider string, subject string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"provider": provider, "subject": subject})
+	return err
+}
+
+func (r IdentityRepository) DeleteUserIdentities(ctx context.Context, userID string) error {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err = r.collection.DeleteMany(ctx, bson.M{"user_id": objectID})
+	return err
+}
@@ -0,0 +1,98 @@
+package mongo_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewLoginAttemptRepository(collection *mongo.Collection, timeout time.Duration) ports.LoginAttemptRepositoryInterface {
+	repository := &LoginAttemptRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create login attempt repository: %v", err)
+	}
+
+	return repository
+}
+
+// EnsureIndexes adds a TTL index, so Mongo forgets a key once its window and lockout are over.
+func (r LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (r LoginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// The TTL monitor runs about once a minute, so expired documents are filtered out here too
+	var attempts domain.LoginAttempts
+	err := r.collection.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&attempts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &domain.LoginAttempts{Key: key}, nil
+		}
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (r LoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "failures", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gte", Value: bson.A{"$last_failure_at", at.Add(-window)}}},
+			bson.D{{Key: "$add", Value: bson.A{"$failures", 1}}},
+			1,
+		}}}},
+		{Key: "last_failure_at", Value: at},
+		{Key: "expires_at", Value: bson.D{{Key: "$max", Value: bson.A{"$locked_until", at.Add(window)}}}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempts domain.LoginAttempts
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempts); err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (r LoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "failures", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$failures", 0}}}},
+		{Key: "locked_until", Value: until},
+		{Key: "expires_at", Value: bson.D{{Key: "$max", Value: bson.A{"$expires_at", until}}}},
+	}}}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r LoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,114 @@
+package mongo_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+// MFARepository keeps one document per user, with the user id as _id.
+type MFARepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewMFARepository(collection *mongo.Collection, timeout time.Duration) ports.MFARepositoryInterface {
+	repository := &MFARepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create MFA repository: %v", err)
+	}
+
+	return repository
+}
+
+// EnsureIndexes has nothing to do, documents are only looked up by _id.
+func (r MFARepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r MFARepository) SaveMFA(ctx context.Context, mfa *domain.MFA) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if mfa.RecoveryCodes == nil {
+		mfa.RecoveryCodes = []string{}
+	}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": mfa.UserID}, mfa, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r MFARepository) GetMFA(ctx context.Context, userID string) (*domain.MFA, error) {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var mfa domain.MFA
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mfa); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+func (r MFARepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "last_used_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_used_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r MFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "recovery_codes": codeHash},
+		bson.M{"$pull": bson.M{"recovery_codes": codeHash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r MFARepository) DeleteMFA(ctx context.Context, userID string) error {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	return err
+}
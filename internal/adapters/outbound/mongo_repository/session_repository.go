@@ -0,0 +1,179 @@
+package mongo_repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"time"
+)
+
+type SessionRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewSessionRepository(collection *mongo.Collection, timeout time.Duration) ports.SessionRepositoryInterface {
+	repository := &SessionRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("could not create session repository: %v", err)
+	}
+
+	return repository
+}
+
+// EnsureIndexes also lets MongoDB drop sessions once they have expired.
+func (r SessionRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+		{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+func (r SessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r SessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var session domain.Session
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r SessionRepository) ListUserSessions(ctx context.Context, userID string, now time.Time) ([]domain.Session, error) {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx,
+		activeSessions(bson.M{"user_id": objectID}, now),
+		options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r SessionRepository) TouchSession(ctx context.Context, id string, tokenID string, lastSeenAt time.Time, expiresAt time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"token_id":     tokenID,
+		"last_seen_at": lastSeenAt,
+		"expires_at":   expiresAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r SessionRepository) MarkSessionSeen(ctx context.Context, id string, seenAt time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$max": bson.M{"last_seen_at": seenAt}})
+	return err
+}
+
+func (r SessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt}},
+	)
+	return err
+}
+
+func (r SessionRepository) RevokeUserSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) ([]string, error) {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := activeSessions(bson.M{"user_id": objectID, "_id": bson.M{"$ne": exceptID}}, revokedAt)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var found []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	// Revoke the ids that were found, a session created meanwhile was not in the caller's view
+	var revoked []string
+	for _, session := range found {
+		revoked = append(revoked, session.ID)
+	}
+	if len(revoked) == 0 {
+		return nil, nil
+	}
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": revoked}, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+func (r SessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err = r.collection.DeleteMany(ctx, bson.M{"user_id": objectID})
+	return err
+}
+
+func activeSessions(filter bson.M, now time.Time) bson.M {
+	filter["revoked_at"] = bson.M{"$exists": false}
+	filter["expires_at"] = bson.M{"$gt": now}
+	return filter
+}
@@ -2,6 +2,7 @@ package mongo_repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,33 +12,42 @@ import (
 	"golang-rest/internal/core/ports"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"regexp"
 	"time"
 )
 
 type UserRepository struct {
 	collection *mongo.Collection
+	timeout    time.Duration
 }
 
-func NewUserRepository(collection *mongo.Collection) ports.UserRepositoryInterface {
-	repository := &UserRepository{collection: collection}
-	if err := repository.EnsureIndexes(); err != nil {
+// NewUserRepository bounds every operation by timeout on top of the caller's context; zero disables the deadline.
+func NewUserRepository(collection *mongo.Collection, timeout time.Duration) ports.UserRepositoryInterface {
+	repository := &UserRepository{collection: collection, timeout: timeout}
+	if err := repository.EnsureIndexes(context.Background()); err != nil {
 		log.Fatalf("could not create user repository: %v", err)
 	}
 
 	return repository
 }
 
-func (u UserRepository) EnsureIndexes() error {
-	indexModel := mongo.IndexModel{
-		Keys:    bson.M{"email": 1},
-		Options: options.Index().SetUnique(true),
-	}
+// EnsureIndexes keeps emails unique among the users that are not deleted: those all share a missing
+// deleted_at, while each deleted user has their own. It replaces the plain email index of older versions.
+func (u UserRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
 
-	_, err := u.collection.Indexes().CreateOne(context.TODO(), indexModel)
+	if _, err := u.collection.Indexes().DropOne(ctx, "email_1"); err != nil && !isIndexNotFound(err) {
+		return err
+	}
+	_, err := u.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "deleted_at", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"deleted_at": 1}, Options: options.Index().SetSparse(true)},
+	})
 	return err
 }
 
-func (u UserRepository) CreateUser(user *domain.User) error {
+func (u UserRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Println("Password hashing failed: ", err)
@@ -45,11 +55,18 @@ func (u UserRepository) CreateUser(user *domain.User) error {
 	}
 	user.Password = string(hashedPassword)
 	user.CreatedAt = time.Now()
+	user.Version = 1
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
 
-	_, err = u.collection.InsertOne(context.Background(), user)
+	_, err = u.collection.InsertOne(ctx, user)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
-			return fmt.Errorf("%w: %s", "email already exists", user.Email)
+			return fmt.Errorf("%w: %s", domain.ErrEmailAlreadyExists, user.Email)
 		}
 		log.Println("MongoDB insert error: ", err)
 		return err
@@ -58,79 +75,342 @@ func (u UserRepository) CreateUser(user *domain.User) error {
 	return err
 }
 
-func (u UserRepository) GetAllUsers() ([]domain.User, error) {
+func (u UserRepository) GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	filter := active(bson.M{})
+	if query.EmailPrefix != "" {
+		filter["email"] = bson.M{"$regex": "^" + regexp.QuoteMeta(query.EmailPrefix)}
+	}
+	if query.NameContains != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(query.NameContains), "$options": "i"}
+	}
+	createdAt := bson.M{}
+	if query.CreatedAfter != nil {
+		createdAt["$gte"] = *query.CreatedAfter
+	}
+	if query.CreatedBefore != nil {
+		createdAt["$lt"] = *query.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		filter["create_at"] = createdAt
+	}
+
+	total, err := u.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// _id breaks ties so pages stay stable when the sort key repeats
+	direction := 1
+	if query.SortDesc {
+		direction = -1
+	}
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = domain.SortByCreatedAt
+	}
 	projection := bson.D{{Key: "password", Value: 0}}
-	findOptions := options.Find().SetProjection(projection)
-	cursor, err := u.collection.Find(context.Background(), bson.M{}, findOptions)
+	findOptions := options.Find().
+		SetProjection(projection).
+		SetSort(bson.D{{Key: sortBy, Value: direction}, {Key: "_id", Value: direction}}).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+	cursor, err := u.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	var users []domain.User
-	if err := cursor.All(context.Background(), &users); err != nil {
+	users := []domain.User{}
+	if err := cursor.All(ctx, &users); err != nil {
 		return nil, err
 	}
-	return users, nil
+	return &domain.UserPage{Users: users, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
 }
 
-func (u UserRepository) GetUserByEmail(email string) (*domain.User, error) {
+func (u UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
 	var user domain.User
 	projection := bson.D{{Key: "password", Value: 0}}
 	findOptions := options.FindOne().SetProjection(projection)
-	err := u.collection.FindOne(context.Background(), bson.M{"email": email}, findOptions).Decode(&user)
+	err := u.collection.FindOne(ctx, active(bson.M{"email": email}), findOptions).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &user, nil
 }
 
-func (u UserRepository) GetUserLoginByEmail(email string) (*domain.User, error) {
+func (u UserRepository) GetUserLoginByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
 	var user domain.User
-	err := u.collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
+	err := u.collection.FindOne(ctx, active(bson.M{"email": email})).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &user, nil
 }
 
-func (u UserRepository) GetUserByID(id string) (*domain.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (u UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
 	var user domain.User
 	projection := bson.D{{Key: "password", Value: 0}}
 	findOptions := options.FindOne().SetProjection(projection)
-	err = u.collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&user)
+	err = u.collection.FindOne(ctx, active(bson.M{"_id": objectID}), findOptions).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &user, nil
 }
 
-func (u UserRepository) UpdateUserByID(id string, updates bson.M) (*domain.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (u UserRepository) UpdateUserByID(ctx context.Context, id string, version int64, userUpdate domain.UserUpdate) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	update := bson.M{"$set": updates}
-	_, err = u.collection.UpdateByID(context.Background(), objectID, update)
+	updateCtx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	var user domain.User
+	filter := active(bson.M{"_id": objectID})
+	if version != domain.AnyVersion {
+		filter["version"] = versionFilter(version)
+	}
+	set := bson.M{}
+	if userUpdate.Name != nil {
+		set["name"] = *userUpdate.Name
+	}
+	if userUpdate.Email != nil {
+		set["email"] = *userUpdate.Email
+	}
+	if userUpdate.EmailVerified != nil {
+		set["email_verified"] = *userUpdate.EmailVerified
+	}
+	update := versioned(bson.M{})
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetProjection(bson.D{{Key: "password", Value: 0}}).
+		SetReturnDocument(options.After)
+	err = u.collection.FindOneAndUpdate(updateCtx, filter, update, findOptions).Decode(&user)
+	switch {
+	case err == nil:
+		return &user, nil
+	case mongo.IsDuplicateKeyError(err):
+		return nil, domain.ErrEmailAlreadyExists
+	case errors.Is(err, mongo.ErrNoDocuments) && version == domain.AnyVersion:
+		return nil, domain.ErrUserNotFound
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, err
+	}
+
+	// Nothing matched: either the user is gone or someone else wrote first
+	if _, err := u.GetUserByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrVersionMismatch
+}
+
+func (u UserRepository) UpdateUserPassword(ctx context.Context, id string, password string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Password hashing failed: ", err)
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	result, err := u.collection.UpdateOne(ctx, active(bson.M{"_id": objectID}), versioned(bson.M{"$set": bson.M{"password": string(hashedPassword)}}))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) MarkUserEmailVerified(ctx context.Context, id string, email string) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	result, err := u.collection.UpdateOne(ctx, active(bson.M{"_id": objectID, "email": email}), versioned(bson.M{"$set": bson.M{"email_verified": true}}))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) DeleteUserByID(ctx context.Context, id string) error {
+	objectID, err := domain.ParseID(id)
 	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	result, err := u.collection.UpdateOne(ctx, active(bson.M{"_id": objectID}), versioned(bson.M{"$set": bson.M{"deleted_at": time.Now()}}))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updateCtx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	filter := bson.M{"_id": objectID, "deleted_at": bson.M{"$exists": true}}
+	result, err := u.collection.UpdateOne(updateCtx, filter, versioned(bson.M{"$unset": bson.M{"deleted_at": ""}}))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrEmailAlreadyExists
+		}
 		return nil, err
 	}
+	if result.MatchedCount == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return u.GetUserByID(ctx, id)
+}
+
+func (u UserRepository) GetDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
 
-	return u.GetUserByID(id)
+	projection := bson.D{{Key: "password", Value: 0}}
+	cursor, err := u.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	users := []domain.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
-func (u UserRepository) DeleteUserByID(id string) error {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (u UserRepository) PurgeUserByID(ctx context.Context, id string, deletedBefore time.Time) error {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	result, err := u.collection.DeleteOne(ctx, bson.M{"_id": objectID, "deleted_at": bson.M{"$lt": deletedBefore}})
 	if err != nil {
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u UserRepository) AddUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(ctx, id, bson.M{"$addToSet": bson.M{"roles": role}})
+}
+
+func (u UserRepository) RemoveUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	return u.updateRoles(ctx, id, bson.M{"$pull": bson.M{"roles": role}})
+}
+
+func (u UserRepository) updateRoles(ctx context.Context, id string, update bson.M) (*domain.User, error) {
+	objectID, err := domain.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, u.timeout)
+	defer cancel()
+
+	var user domain.User
+	findOptions := options.FindOneAndUpdate().
+		SetProjection(bson.D{{Key: "password", Value: 0}}).
+		SetReturnDocument(options.After)
+	err = u.collection.FindOneAndUpdate(ctx, active(bson.M{"_id": objectID}), versioned(update), findOptions).Decode(&user)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+// active narrows filter to the users that are not deleted.
+func active(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// versioned bumps the version along with update, so every write invalidates the ETags handed out before it.
+func versioned(update bson.M) bson.M {
+	update["$inc"] = bson.M{"version": 1}
+	return update
+}
+
+// versionFilter matches version, where users stored before versioning count as version 0.
+func versionFilter(version int64) bson.M {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return bson.M{"$eq": version}
+}
+
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	// 27 is IndexNotFound, 26 NamespaceNotFound for a collection that does not exist yet
+	return errors.As(err, &commandErr) && (commandErr.Code == 27 || commandErr.Code == 26)
+}
 
-	_, err = u.collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.ErrUserNotFound
+	}
 	return err
 }
+
+// withTimeout applies the per-operation deadline on top of ctx; zero disables it.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
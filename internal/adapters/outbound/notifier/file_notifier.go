@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends every notification to a file as one JSON object per line.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) ports.Notifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	line, err := json.Marshal(struct {
+		domain.Notification
+		SentAt time.Time `json:"sent_at"`
+	}{notification, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+)
+
+// LogNotifier writes every notification to the application log, for local runs without a mail server.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) ports.Notifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.logger.Printf("Notification to %s: %s\n%s", notification.To, notification.Subject, notification.Body)
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// APIKeyPrefix starts every key, so a leaked one is easy to recognise.
+const APIKeyPrefix = "grk_"
+
+// APIKey lets a service call the API without a user account. Only the hash of the key is stored;
+// Prefix is its public part, used to look it up and to tell keys apart in listings.
+type APIKey struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Prefix    string             `bson:"prefix" json:"prefix"`
+	KeyHash   string             `bson:"key_hash" json:"-"`
+	Scopes    []Permission       `bson:"scopes" json:"scopes"`
+	CreatedBy primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k APIKey) Principal() *Principal {
+	principal := &Principal{APIKeyID: k.ID.Hex(), Roles: []string{}, Scopes: k.Scopes}
+	if k.ExpiresAt != nil {
+		principal.ExpiresAt = *k.ExpiresAt
+	}
+	if principal.Scopes == nil {
+		principal.Scopes = []Permission{}
+	}
+	return principal
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Audit actions are named after what they touch and what happened to it.
+const (
+	AuditUserRegister   = "user.register"
+	AuditUserUpdate     = "user.update"
+	AuditUserDelete     = "user.delete"
+	AuditUserRestore    = "user.restore"
+	AuditUserPurge      = "user.purge"
+	AuditRoleGrant      = "user.role_grant"
+	AuditRoleRevoke     = "user.role_revoke"
+	AuditLogin          = "auth.login"
+	AuditLoginMFA       = "auth.login_mfa"
+	AuditLoginOIDC      = "auth.login_oidc"
+	AuditTokenRefresh   = "auth.refresh"
+	AuditLogout         = "auth.logout"
+	AuditUnlock         = "auth.unlock"
+	AuditRevokeTokens   = "auth.revoke_tokens"
+	AuditPasswordChange = "password.change"
+	AuditPasswordForgot = "password.forgot"
+	AuditPasswordReset  = "password.reset"
+	AuditEmailVerify    = "email.verify"
+	AuditMFAEnroll      = "mfa.enroll"
+	AuditMFAConfirm     = "mfa.confirm"
+	AuditMFARecovery    = "mfa.recovery_codes"
+	AuditMFADisable     = "mfa.disable"
+	AuditMFAReset       = "mfa.reset"
+	AuditSessionRevoke  = "session.revoke"
+	AuditSessionsRevoke = "session.revoke_others"
+	AuditAPIKeyCreate   = "api_key.create"
+	AuditAPIKeyRevoke   = "api_key.revoke"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	// AuditOutcomeMFA is a correct password that still waits for the second factor.
+	AuditOutcomeMFA = "mfa_required"
+
+	AuditActorUser   = "user"
+	AuditActorAPIKey = "api_key"
+	// AuditActorSystem is a background job, it has no ActorID.
+	AuditActorSystem = "system"
+
+	DefaultAuditPageLimit = 50
+	MaxAuditPageLimit     = 500
+)
+
+// AuditChange is one field of an update, before and after.
+type AuditChange struct {
+	From string `bson:"from" json:"from"`
+	To   string `bson:"to" json:"to"`
+}
+
+// AuditEvent records who did what to which user, from where and how it ended.
+type AuditEvent struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Action string             `bson:"action" json:"action"`
+	// Outcome is AuditOutcomeSuccess, AuditOutcomeFailure with the error code in Reason, or AuditOutcomeMFA.
+	Outcome string `bson:"outcome" json:"outcome"`
+	Reason  string `bson:"reason,omitempty" json:"reason,omitempty"`
+	// ActorID is the user or API key behind the request, empty when it was anonymous or the system.
+	ActorID   string `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	ActorType string `bson:"actor_type,omitempty" json:"actor_type,omitempty"`
+	// TargetID is the user acted on, or the key for the api_key actions.
+	TargetID string `bson:"target_id,omitempty" json:"target_id,omitempty"`
+	// TargetEmail names the account when the request only had an email, like a failed login.
+	TargetEmail string                 `bson:"target_email,omitempty" json:"target_email,omitempty"`
+	Changes     map[string]AuditChange `bson:"changes,omitempty" json:"changes,omitempty"`
+	IP          string                 `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent   string                 `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	RequestID   string                 `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// AuditQuery filters on every field that is set; events come newest first.
+type AuditQuery struct {
+	Limit       int
+	Offset      int
+	Action      string
+	Outcome     string
+	ActorID     string
+	TargetID    string
+	TargetEmail string
+	From        *time.Time
+	To          *time.Time
+}
+
+func (q AuditQuery) Matches(event AuditEvent) bool {
+	switch {
+	case q.Action != "" && event.Action != q.Action,
+		q.Outcome != "" && event.Outcome != q.Outcome,
+		q.ActorID != "" && event.ActorID != q.ActorID,
+		q.TargetID != "" && event.TargetID != q.TargetID,
+		q.TargetEmail != "" && event.TargetEmail != q.TargetEmail,
+		q.From != nil && event.CreatedAt.Before(*q.From),
+		q.To != nil && !event.CreatedAt.Before(*q.To):
+		return false
+	}
+	return true
+}
+
+type AuditPage struct {
+	Events []AuditEvent `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+func (p AuditPage) HasNext() bool {
+	return int64(p.Offset+len(p.Events)) < p.Total
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Error kinds. Adapters map a kind to a transport status; match them with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidID    = errors.New("invalid id")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+	// ErrPreconditionFailed means the caller wrote against a stale version of the resource.
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// Error is a sentinel with a kind and a stable machine-readable code.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func NewError(kind error, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+var (
+	ErrMissingFields      = NewError(ErrInvalidInput, "missing_fields", "missing fields")
+	ErrUserNotFound       = NewError(ErrNotFound, "user_not_found", "user not found")
+	ErrEmailAlreadyExists = NewError(ErrConflict, "email_already_exists", "email already exists")
+	ErrInvalidCredentials = NewError(ErrUnauthorized, "invalid_credentials", "invalid email or password")
+	ErrIncorrectPassword  = NewError(ErrForbidden, "incorrect_password", "current password is incorrect")
+	ErrTokenGeneration    = NewError(nil, "token_generation_failed", "cannot generate token")
+	ErrInvalidQuery       = NewError(ErrInvalidInput, "invalid_query", "invalid query")
+	ErrInvalidToken       = NewError(ErrUnauthorized, "invalid_token", "invalid or expired token")
+	ErrTokenReused        = NewError(ErrUnauthorized, "token_reused", "refresh token reuse detected")
+	ErrTokenRevoked       = NewError(ErrUnauthorized, "token_revoked", "token has been revoked")
+	ErrInvalidAPIKey      = NewError(ErrUnauthorized, "invalid_api_key", "invalid, expired or revoked API key")
+	ErrAPIKeyNotFound     = NewError(ErrNotFound, "api_key_not_found", "API key not found")
+	ErrSessionNotFound    = NewError(ErrNotFound, "session_not_found", "session not found")
+	ErrUnknownProvider    = NewError(ErrNotFound, "unknown_provider", "unknown identity provider")
+	ErrIdentityNotFound   = NewError(ErrNotFound, "identity_not_found", "identity not found")
+	ErrIdentityLinked     = NewError(ErrConflict, "identity_already_linked", "identity is already linked to a user")
+	ErrInvalidOIDCState   = NewError(ErrUnauthorized, "invalid_state", "login session is missing, expired or does not match")
+	ErrOIDCLoginFailed    = NewError(ErrUnauthorized, "oidc_login_failed", "identity provider login failed")
+	ErrMFARequired        = NewError(ErrUnauthorized, "mfa_required", "a second factor is required")
+	ErrInvalidMFACode     = NewError(ErrUnauthorized, "invalid_mfa_code", "invalid or already used code")
+	ErrMFANotEnrolled     = NewError(ErrNotFound, "mfa_not_enrolled", "multi-factor authentication is not set up")
+	ErrMFAAlreadyEnabled  = NewError(ErrConflict, "mfa_already_enabled", "multi-factor authentication is already on")
+	ErrInvalidRole        = NewError(ErrInvalidInput, "invalid_role", "invalid role")
+	ErrEmailNotVerified   = NewError(ErrForbidden, "email_not_verified", "email not verified")
+	ErrInvalidUserID      = NewError(ErrInvalidID, "invalid_id", "invalid id")
+	ErrPermissionDenied   = NewError(ErrForbidden, "insufficient_permission", "forbidden")
+	ErrNotOwner           = NewError(ErrForbidden, "not_owner", "you can only access your own account")
+	ErrAPIKeyNotAllowed   = NewError(ErrForbidden, "api_key_not_allowed", "API keys cannot do this")
+	ErrLoginThrottled     = NewError(ErrRateLimited, "too_many_attempts", "too many failed login attempts, try again later")
+	ErrRateLimitExceeded  = NewError(ErrRateLimited, "rate_limited", "too many requests, slow down")
+	ErrVersionMismatch    = NewError(ErrPreconditionFailed, "version_mismatch", "the user was changed since it was read")
+	ErrIfMatchRequired    = NewError(ErrPreconditionRequired, "if_match_required", "send the user's ETag in If-Match")
+)
+
+// RetryError tells the caller when Err stops applying, e.g. when a lockout ends.
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// MFARequiredError ends a login step that still needs a second factor; Token starts the next step.
+type MFARequiredError struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (e *MFARequiredError) Error() string {
+	return ErrMFARequired.Error()
+}
+
+func (e *MFARequiredError) Unwrap() error {
+	return ErrMFARequired
+}
+
+// ParseID turns a hex id from a request into an ObjectID, failing with ErrInvalidUserID.
+func ParseID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidUserID
+	}
+	return objectID, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+)
+
+// LoginAttempts counts recent failed logins for one key, an account or a client IP.
+type LoginAttempts struct {
+	Key           string     `bson:"_id" json:"key"`
+	Failures      int        `bson:"failures" json:"failures"`
+	LastFailureAt time.Time  `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+}
+
+func (a LoginAttempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+type LockoutPolicy struct {
+	// MaxAccountFailures and MaxIPFailures lock the key once reached; zero disables that check.
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+	// FailureWindow is how long a quiet key keeps its count before starting over.
+	FailureWindow time.Duration
+	// After n failures the next attempt has to wait BaseDelay * 2^(n-1), at most MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxAccountFailures: 5,
+	MaxIPFailures:      20,
+	LockoutDuration:    15 * time.Minute,
+	FailureWindow:      15 * time.Minute,
+	BaseDelay:          time.Second,
+	MaxDelay:           30 * time.Second,
+}
+
+// Delay is how long to wait after the given number of consecutive failures.
+func (p LockoutPolicy) Delay(failures int) time.Duration {
+	if failures <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
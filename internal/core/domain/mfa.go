@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
+	"time"
+)
+
+// TOTP parameters from RFC 6238, the ones every authenticator app supports.
+const (
+	TOTPPeriod = 30 * time.Second
+	TOTPDigits = 6
+)
+
+// MFA is the second factor of one user. It stays pending until a first code confirms the enrollment;
+// RecoveryCodes only holds hashes and LastUsedStep keeps every TOTP code single-use.
+type MFA struct {
+	UserID        primitive.ObjectID `bson:"_id"`
+	Secret        string             `bson:"secret"`
+	RecoveryCodes []string           `bson:"recovery_codes"`
+	LastUsedStep  int64              `bson:"last_used_step"`
+	EnabledAt     *time.Time         `bson:"enabled_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+func (m MFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// TOTPStep is the number of periods since the Unix epoch at t.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode is the HOTP value (RFC 4226, HMAC-SHA1) of key for one time step.
+func TOTPCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	h := hmac.New(sha1.New, key)
+	h.Write(counter[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000)
+}
+
+// TOTPProvisioningURI is the otpauth:// URI authenticator apps read, usually from a QR code.
+func TOTPProvisioningURI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+	return (&url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + issuer + ":" + account, RawQuery: query.Encode()}).String()
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// bcrypt ignores everything past 72 bytes, so longer passwords are refused instead of silently truncated.
+const MaxPasswordBytes = 72
+
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, RequireLower: true, RequireDigit: true}
+
+// Check returns a FieldError code and message, or an empty code when the password is acceptable.
+func (p PasswordPolicy) Check(password string) (string, string) {
+	if len(password) > MaxPasswordBytes {
+		return ValidationTooLong, "must be at most 72 bytes"
+	}
+	if len([]rune(password)) < p.MinLength {
+		return ValidationTooShort, "must be at least " + strconv.Itoa(p.MinLength) + " characters"
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	switch {
+	case p.RequireUpper && !upper:
+		return ValidationWeakPassword, "must contain an upper case letter"
+	case p.RequireLower && !lower:
+		return ValidationWeakPassword, "must contain a lower case letter"
+	case p.RequireDigit && !digit:
+		return ValidationWeakPassword, "must contain a digit"
+	case p.RequireSymbol && !symbol:
+		return ValidationWeakPassword, "must contain a symbol"
+	}
+	return "", ""
+}
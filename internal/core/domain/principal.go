@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is the authenticated caller, as described by a validated access token or API key.
+type Principal struct {
+	UserID    string
+	Email     string
+	Roles     []string
+	TokenID   string
+	SessionID string
+	ExpiresAt time.Time
+	// APIKeyID is set instead of UserID for API keys, which are limited to their Scopes.
+	APIKeyID string
+	Scopes   []Permission
+}
+
+func (p *Principal) HasPermission(permission Permission) bool {
+	if p.APIKeyID != "" {
+		return slices.Contains(p.Scopes, permission)
+	}
+	return HasPermission(p.Roles, permission)
+}
+
+// CanAccessUser is CanAccessUser for this caller; an API key never owns a user record.
+func (p *Principal) CanAccessUser(targetID string, permission Permission) bool {
+	if p.APIKeyID == "" && p.UserID != "" && p.UserID == targetID {
+		return true
+	}
+	return p.HasPermission(permission)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns nil when the request was not authenticated.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
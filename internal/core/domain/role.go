@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"slices"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+type Permission string
+
+const (
+	PermissionUsersList   Permission = "users:list"
+	PermissionUsersRead   Permission = "users:read"
+	PermissionUsersUpdate Permission = "users:update"
+	PermissionUsersDelete Permission = "users:delete"
+	// PermissionUsersRestore brings a deleted user back before the purge removes them.
+	PermissionUsersRestore Permission = "users:restore"
+	PermissionRolesManage  Permission = "roles:manage"
+	PermissionUsersUnlock  Permission = "users:unlock"
+	// PermissionSessionsRevoke logs another user out of every session.
+	PermissionSessionsRevoke Permission = "sessions:revoke"
+	PermissionAPIKeysManage  Permission = "api_keys:manage"
+	PermissionAuditRead      Permission = "audit:read"
+	// PermissionMFAReset turns off the MFA of a user who lost their second factor; API keys never can.
+	PermissionMFAReset Permission = "mfa:reset"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermissionUsersList,
+		PermissionUsersRead,
+		PermissionUsersUpdate,
+		PermissionUsersDelete,
+		PermissionUsersRestore,
+		PermissionRolesManage,
+		PermissionUsersUnlock,
+		PermissionSessionsRevoke,
+		PermissionAPIKeysManage,
+		PermissionAuditRead,
+		PermissionMFAReset,
+	},
+	// Plain users only reach their own record, which CanAccessUser always allows
+	RoleUser: {},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// IsValidPermission accepts every permission there is, which is exactly what admins hold.
+func IsValidPermission(permission Permission) bool {
+	return slices.Contains(rolePermissions[RoleAdmin], permission)
+}
+
+func HasPermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessUser lets a caller act on their own record, and on anybody else's only with the given permission.
+func CanAccessUser(callerID string, roles []string, targetID string, permission Permission) bool {
+	if callerID != "" && callerID == targetID {
+		return true
+	}
+	return HasPermission(roles, permission)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Session is one login, from the password, MFA or identity provider step until it is logged out,
+// revoked or left to expire. Its ID is the refresh token family and access tokens carry it as sid.
+type Session struct {
+	ID        string             `bson:"_id" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserAgent string             `bson:"user_agent" json:"user_agent"`
+	IP        string             `bson:"ip" json:"ip"`
+	// TokenID is the jti of the last access token issued to the session.
+	TokenID string `bson:"token_id" json:"token_id"`
+	// LastSeenAt moves whenever the session refreshes its tokens or uses its access token.
+	LastSeenAt time.Time  `bson:"last_seen_at" json:"last_seen_at"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time  `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	// Current marks the session of the caller in listings, it is never stored.
+	Current bool `bson:"-" json:"current"`
+}
+
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -6,9 +6,34 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email     string             `bson:"email" json:"email"`
-	Name      string             `bson:"name" json:"name"`
-	Password  string             `bson:"password,omitempty" json:"password"`
-	CreatedAt time.Time          `bson:"create_at,omitempty" json:"create_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email         string             `bson:"email" json:"email"`
+	Name          string             `bson:"name" json:"name"`
+	Password      string             `bson:"password,omitempty" json:"password"`
+	Roles         []string           `bson:"roles,omitempty" json:"roles"`
+	EmailVerified bool               `bson:"email_verified" json:"email_verified"`
+	CreatedAt     time.Time          `bson:"create_at,omitempty" json:"create_at"`
+	// Version goes up with every write; users stored before versioning read as 0.
+	Version int64 `bson:"version" json:"version"`
+	// DeletedAt is set while the user waits in the bin to be restored or purged.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// AnyVersion makes UpdateUserByID skip the version check and write over whatever version is current.
+const AnyVersion int64 = -1
+
+// UserUpdate lists what UpdateUserByID may change, a nil field stays as it is.
+// The password and roles have their own repository methods.
+type UserUpdate struct {
+	Name          *string
+	Email         *string
+	EmailVerified *bool
+}
+
+// EffectiveRoles treats accounts created before roles existed as plain users.
+func (u User) EffectiveRoles() []string {
+	if len(u.Roles) == 0 {
+		return []string{RoleUser}
+	}
+	return u.Roles
 }
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	SortByName      = "name"
+	SortByEmail     = "email"
+	SortByCreatedAt = "create_at"
+
+	DefaultUserPageLimit = 20
+	MaxUserPageLimit     = 100
+)
+
+type UserQuery struct {
+	Limit         int
+	Offset        int
+	SortBy        string
+	SortDesc      bool
+	EmailPrefix   string
+	NameContains  string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+type UserPage struct {
+	Users  []User `json:"users"`
+	Total  int64  `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// HasNext reports whether another page follows this one.
+func (p UserPage) HasNext() bool {
+	return int64(p.Offset+len(p.Users)) < p.Total
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"strings"
+)
+
+// Machine-readable codes for FieldError.Code.
+const (
+	ValidationRequired     = "required"
+	ValidationInvalidEmail = "invalid_email"
+	ValidationTooShort     = "too_short"
+	ValidationTooLong      = "too_long"
+	ValidationWeakPassword = "weak_password"
+	ValidationUnknownField = "unknown_field"
+	ValidationInvalidType  = "invalid_type"
+	ValidationInvalidValue = "invalid_value"
+)
+
+var ErrValidation = NewError(ErrInvalidInput, "validation_failed", "validation failed")
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every invalid field of a payload instead of stopping at the first.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field string, code string, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns nil when no field was added, so callers can end with `return v.Err()`.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, field.Field+": "+field.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, ", ")
+}
+
+// Is keeps errors.Is(err, ErrMissingFields) working for callers that predate field level errors.
+func (e *ValidationError) Is(target error) bool {
+	if target == ErrValidation || target == ErrInvalidInput {
+		return true
+	}
+	if target == ErrMissingFields {
+		for _, field := range e.Fields {
+			if field.Code == ValidationRequired {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type APIKeyRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	// GetAPIKeyByPrefix also returns expired and revoked keys, it is up to the caller to refuse them.
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	// RevokeAPIKey keeps the first revocation time of a key that was already revoked.
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+}
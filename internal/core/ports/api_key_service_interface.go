@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type CreateAPIKeyInput struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is optional, a key without it works until it is revoked.
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey carries the plain key, which is only ever shown once.
+type CreatedAPIKey struct {
+	APIKey domain.APIKey
+	Key    string
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, input CreateAPIKeyInput) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	// AuthenticateAPIKey returns the principal of an active key and domain.ErrInvalidAPIKey for anything else.
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error)
+}
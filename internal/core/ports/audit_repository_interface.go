@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type AuditRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	// RecordAuditEvent assigns the event an ID when it has none.
+	RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error
+	ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error)
+}
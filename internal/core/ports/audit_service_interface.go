@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type AuditService interface {
+	ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error)
+}
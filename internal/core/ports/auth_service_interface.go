@@ -0,0 +1,50 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type TokenPair struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
+
+// TokenRevoker ends every session of a user: refresh tokens stop working and access tokens issued so far are refused.
+type TokenRevoker interface {
+	RevokeUserTokens(ctx context.Context, id string) error
+	// RevokeUserTokensExcept keeps the session the user is acting from; without a session
+	// store the sessions cannot be told apart and it ends all of them like RevokeUserTokens.
+	RevokeUserTokensExcept(ctx context.Context, id string, sessionID string) error
+}
+
+// TokenIssuer logs a user in who has already been authenticated some other way, e.g. by an identity provider.
+// Users with MFA on get a *domain.MFARequiredError instead of tokens.
+type TokenIssuer interface {
+	IssueTokens(ctx context.Context, user *domain.User) (*TokenPair, error)
+}
+
+// CredentialGuard counts the wrong passwords and codes of users who are already logged in as failed logins,
+// with the same lockout and progressive delay, so a stolen session cannot be used to guess them either.
+type CredentialGuard interface {
+	// GuardCredential refuses with domain.ErrLoginThrottled while the email or client is throttled and otherwise
+	// runs verify; domain.ErrInvalidCredentials, domain.ErrIncorrectPassword and domain.ErrInvalidMFACode count as failures.
+	GuardCredential(ctx context.Context, email string, verify func() error) error
+}
+
+type AuthService interface {
+	TokenIssuer
+	TokenRevoker
+	CredentialGuard
+	// LoginUser returns a *domain.MFARequiredError when the user still has to pass VerifyMFA.
+	LoginUser(ctx context.Context, email string, password string) (*TokenPair, error)
+	// VerifyMFA finishes a login with the token from domain.MFARequiredError and a TOTP or recovery code.
+	VerifyMFA(ctx context.Context, mfaToken string, code string) (*TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout also revokes the access token of the domain.Principal in ctx, when there is one.
+	Logout(ctx context.Context, refreshToken string) error
+	// UnlockUser clears the failed login count and any lockout of the user's account.
+	UnlockUser(ctx context.Context, id string) error
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type EmailVerificationService interface {
+	SendVerificationEmail(ctx context.Context, user *domain.User) error
+	// ResendVerificationEmail does nothing for an account that is already verified.
+	ResendVerificationEmail(ctx context.Context, id string) error
+	// RequestVerificationEmail is the resend for callers who cannot log in yet; like
+	// RequestPasswordReset it succeeds whether or not the account exists.
+	RequestVerificationEmail(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+}
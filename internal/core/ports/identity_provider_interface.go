@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+// IdentityProvider is an OpenID Connect provider we act as relying party for.
+type IdentityProvider interface {
+	// AuthCodeURL is where the browser logs in; codeVerifier is sent as its S256 PKCE challenge.
+	AuthCodeURL(state string, nonce string, codeVerifier string) string
+	// Exchange redeems the code and returns the profile from the ID token, once its
+	// signature, issuer, audience, expiry and nonce have been checked.
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.ExternalProfile, error)
+}
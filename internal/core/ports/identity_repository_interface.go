@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type IdentityRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	// CreateIdentity returns domain.ErrIdentityLinked when the provider subject is already linked.
+	CreateIdentity(ctx context.Context, identity *domain.Identity) error
+	GetIdentity(ctx context.Context, provider string, subject string) (*domain.Identity, error)
+	DeleteIdentity(ctx context.Context, provider string, subject string) error
+	DeleteUserIdentities(ctx context.Context, userID string) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type LoginAttemptRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	// GetLoginAttempts returns an empty record for a key without recent failures.
+	GetLoginAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error)
+	// RecordLoginFailure atomically counts one failure at the given time; a key whose
+	// last failure is older than window starts over from one.
+	RecordLoginFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type MFARepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	// SaveMFA replaces whatever the user had before, e.g. a pending enrollment with a new one.
+	SaveMFA(ctx context.Context, mfa *domain.MFA) error
+	// GetMFA returns domain.ErrMFANotEnrolled when the user has none.
+	GetMFA(ctx context.Context, userID string) (*domain.MFA, error)
+	// UseTOTPStep records step as used unless it, or a later one, was used before.
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode removes the code and reports whether it was still there.
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	DeleteMFA(ctx context.Context, userID string) error
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+)
+
+// TOTPEnrollment is shown to the user once, ProvisioningURI is meant for a QR code.
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAService manages the second factor of the user in the domain.Principal of ctx.
+type MFAService interface {
+	// EnrollTOTP starts over with a new secret, which only counts once ConfirmTOTP accepts a code from it.
+	EnrollTOTP(ctx context.Context, userID string) (*TOTPEnrollment, error)
+	// ConfirmTOTP turns MFA on and returns the recovery codes; they are never shown again.
+	ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error)
+	// DisableMFA is for the user themselves and needs a current code.
+	DisableMFA(ctx context.Context, userID string, code string) error
+	// ResetMFA turns off the MFA of a user who lost their second factor, without a code. It needs
+	// domain.PermissionMFAReset and a user behind the request, API keys are refused.
+	ResetMFA(ctx context.Context, userID string) error
+}
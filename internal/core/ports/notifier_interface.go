@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+// Notifier delivers messages to users, e.g. password reset links.
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+)
+
+// OIDCAuthorization is where StartLogin sends the browser. Session is signed and carries the
+// state, nonce and PKCE verifier; it has to come back with the callback, e.g. in a cookie.
+type OIDCAuthorization struct {
+	URL     string
+	Session string
+}
+
+type OIDCService interface {
+	StartLogin(ctx context.Context, provider string) (*OIDCAuthorization, error)
+	// CompleteLogin checks the callback against its session, links or creates the user and issues our own tokens.
+	CompleteLogin(ctx context.Context, provider string, session string, state string, code string) (*TokenPair, error)
+}
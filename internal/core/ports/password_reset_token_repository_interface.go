@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type PasswordResetTokenRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	// MarkPasswordResetTokenUsed sets used_at only if it is still unset and reports whether it did.
+	MarkPasswordResetTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	DeleteUserPasswordResetTokens(ctx context.Context, userID string) error
+}
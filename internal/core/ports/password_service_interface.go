@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+)
+
+type PasswordService interface {
+	ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error
+	// RequestPasswordReset succeeds for unknown emails too, so it cannot be used to probe for accounts.
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type RefreshTokenRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// MarkRefreshTokenUsed sets used_at only if it is still unset and reports whether it did,
+	// so two concurrent rotations of the same token cannot both succeed.
+	MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error
+	DeleteUserRefreshTokens(ctx context.Context, userID string) error
+}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+type SessionRepositoryInterface interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateSession(ctx context.Context, session *domain.Session) error
+	// GetSession returns domain.ErrSessionNotFound for unknown ids.
+	GetSession(ctx context.Context, id string) (*domain.Session, error)
+	// ListUserSessions returns the sessions that are neither revoked nor expired at now, the last seen first.
+	ListUserSessions(ctx context.Context, userID string, now time.Time) ([]domain.Session, error)
+	// TouchSession records a refresh: the new access token and until when the session now lasts.
+	// It returns domain.ErrSessionNotFound for unknown ids.
+	TouchSession(ctx context.Context, id string, tokenID string, lastSeenAt time.Time, expiresAt time.Time) error
+	// MarkSessionSeen records that the session's access token was used; LastSeenAt only ever moves forward.
+	MarkSessionSeen(ctx context.Context, id string, seenAt time.Time) error
+	RevokeSession(ctx context.Context, id string, revokedAt time.Time) error
+	// RevokeUserSessions revokes the user's active sessions except exceptID, which may be empty,
+	// and returns the ids it revoked.
+	RevokeUserSessions(ctx context.Context, userID string, exceptID string, revokedAt time.Time) ([]string, error)
+	DeleteUserSessions(ctx context.Context, userID string) error
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+// SessionService lets users and admins see and end logins; the domain.Principal in ctx
+// tells which session is the caller's own.
+type SessionService interface {
+	ListSessions(ctx context.Context, userID string) ([]domain.Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	// RevokeOtherSessions ends every session of the user but the caller's own.
+	RevokeOtherSessions(ctx context.Context, userID string) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// TokenRevocationRepositoryInterface keeps access tokens that must stop working before they expire.
+// Entries are only needed until expiresAt, when the tokens they cover would be refused anyway.
+type TokenRevocationRepositoryInterface interface {
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// RevokeUserAccessTokens revokes every token of the user issued before issuedBefore;
+	// an earlier cut-off than the one already stored is ignored.
+	RevokeUserAccessTokens(ctx context.Context, userID string, issuedBefore time.Time, expiresAt time.Time) error
+	// RevokeSession revokes every token issued to the session, see domain.Session.
+	RevokeSession(ctx context.Context, sessionID string, expiresAt time.Time) error
+	// IsAccessTokenRevoked takes an empty sessionID for tokens from before sessions existed.
+	IsAccessTokenRevoked(ctx context.Context, tokenID string, sessionID string, userID string, issuedAt time.Time) (bool, error)
+}
@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"golang-rest/internal/core/domain"
+)
+
+// AccessTokenClaims is what an access token carries; the subject is the user id.
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+	// SessionID is the sid of the login the token belongs to, see domain.Session.
+	SessionID string `json:"sid,omitempty"`
+}
+
+// Validate runs after the registered claim checks: a token has to name its user, carry an id and say when it was issued.
+func (c AccessTokenClaims) Validate() error {
+	if c.Subject == "" || c.ID == "" || c.IssuedAt == nil {
+		return errors.New("access token needs sub, jti and iat")
+	}
+	return nil
+}
+
+func (c AccessTokenClaims) Principal() *domain.Principal {
+	principal := &domain.Principal{UserID: c.Subject, Email: c.Email, Roles: c.Roles, TokenID: c.ID, SessionID: c.SessionID}
+	if c.ExpiresAt != nil {
+		principal.ExpiresAt = c.ExpiresAt.Time
+	}
+	if principal.Roles == nil {
+		principal.Roles = []string{}
+	}
+	return principal
+}
+
+// TokenSigner signs access tokens, the keys themselves stay outside the core.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"time"
+)
+
+// UserPurgeService removes the users deleted longer ago than the retention, together with
+// everything kept for them.
+type UserPurgeService interface {
+	GetPurgeableUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error)
+	// PurgeUser returns domain.ErrUserNotFound when the user was restored or purged meanwhile.
+	PurgeUser(ctx context.Context, user domain.User, deletedBefore time.Time) error
+}
@@ -1,17 +1,36 @@
 package ports
 
 import (
-	"go.mongodb.org/mongo-driver/bson"
+	"context"
 	"golang-rest/internal/core/domain"
+	"time"
 )
 
+// UserRepositoryInterface only ever returns users that are not deleted, except for RestoreUserByID.
 type UserRepositoryInterface interface {
-	EnsureIndexes() error
-	CreateUser(user *domain.User) error
-	GetAllUsers() ([]domain.User, error)
-	GetUserByEmail(email string) (*domain.User, error)
-	GetUserLoginByEmail(email string) (*domain.User, error)
-	GetUserByID(id string) (*domain.User, error)
-	UpdateUserByID(id string, updates bson.M) (*domain.User, error)
-	DeleteUserByID(id string) error
+	EnsureIndexes(ctx context.Context) error
+	CreateUser(ctx context.Context, user *domain.User) error
+	GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserLoginByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+	// UpdateUserByID only applies while the user is still at version and returns the document it wrote,
+	// it fails with domain.ErrVersionMismatch when someone else wrote first. domain.AnyVersion skips the check.
+	UpdateUserByID(ctx context.Context, id string, version int64, update domain.UserUpdate) (*domain.User, error)
+	// UpdateUserPassword hashes the plain password, like CreateUser does.
+	UpdateUserPassword(ctx context.Context, id string, password string) error
+	// MarkUserEmailVerified only applies while the account still has that email,
+	// so a token sent to an old address cannot verify a new one.
+	MarkUserEmailVerified(ctx context.Context, id string, email string) error
+	// DeleteUserByID marks the user deleted, their email becomes free for a new account.
+	DeleteUserByID(ctx context.Context, id string) error
+	// RestoreUserByID fails with domain.ErrEmailAlreadyExists when the email was taken again meanwhile.
+	RestoreUserByID(ctx context.Context, id string) (*domain.User, error)
+	// GetDeletedUsers returns the users deleted before the cut-off, without their passwords.
+	GetDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error)
+	// PurgeUserByID removes the user for good, but only while they are still deleted since before
+	// the cut-off, so a user restored meanwhile stays; otherwise it returns domain.ErrUserNotFound.
+	PurgeUserByID(ctx context.Context, id string, deletedBefore time.Time) error
+	AddUserRole(ctx context.Context, id string, role string) (*domain.User, error)
+	RemoveUserRole(ctx context.Context, id string, role string) (*domain.User, error)
 }
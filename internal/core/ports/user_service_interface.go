@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+)
+
+type RegisterUserInput struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// UpdateUserInput only carries the fields a caller may change; nil means "leave unchanged".
+type UpdateUserInput struct {
+	Name  *string
+	Email *string
+	// Version is the version the caller last read; nil updates whatever version is current at the time of the write.
+	Version *int64
+}
+
+type UserService interface {
+	RegisterUser(ctx context.Context, input RegisterUserInput) (*domain.User, error)
+	GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error)
+	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+	UpdateUserByID(ctx context.Context, id string, input UpdateUserInput) (*domain.User, error)
+	DeleteUserByID(ctx context.Context, id string) error
+	RestoreUserByID(ctx context.Context, id string) (*domain.User, error)
+	GrantRole(ctx context.Context, id string, role string) (*domain.User, error)
+	RevokeRole(ctx context.Context, id string, role string) (*domain.User, error)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"slices"
+	"strings"
+	"time"
+)
+
+type APIKeyService struct {
+	apiKeyRepository ports.APIKeyRepositoryInterface
+}
+
+func NewAPIKeyService(apiKeyRepository ports.APIKeyRepositoryInterface) ports.APIKeyService {
+	return &APIKeyService{apiKeyRepository: apiKeyRepository}
+}
+
+func (s APIKeyService) CreateAPIKey(ctx context.Context, input ports.CreateAPIKeyInput) (*ports.CreatedAPIKey, error) {
+	now := time.Now()
+	var v domain.ValidationError
+	validateName(&v, input.Name)
+	scopes := make([]domain.Permission, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		permission := domain.Permission(scope)
+		if !domain.IsValidPermission(permission) {
+			v.Add("scopes", domain.ValidationInvalidValue, "unknown scope "+scope)
+			continue
+		}
+		if !slices.Contains(scopes, permission) {
+			scopes = append(scopes, permission)
+		}
+	}
+	if len(input.Scopes) == 0 {
+		v.Add("scopes", domain.ValidationRequired, "is required")
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		v.Add("expires_at", domain.ValidationInvalidValue, "must be in the future")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, 8)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	apiKey := &domain.APIKey{
+		Name:      strings.TrimSpace(input.Name),
+		Prefix:    hex.EncodeToString(prefix),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: input.ExpiresAt,
+	}
+	key := domain.APIKeyPrefix + apiKey.Prefix + "_" + secret
+	apiKey.KeyHash = hashToken(key)
+	if principal := domain.PrincipalFrom(ctx); principal != nil {
+		apiKey.CreatedBy, _ = domain.ParseID(principal.UserID)
+	}
+
+	if err := s.apiKeyRepository.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	return &ports.CreatedAPIKey{APIKey: *apiKey, Key: key}, nil
+}
+
+func (s APIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.apiKeyRepository.ListAPIKeys(ctx)
+}
+
+func (s APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	return s.apiKeyRepository.RevokeAPIKey(ctx, id, time.Now())
+}
+
+func (s APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	// A key is grk_<prefix>_<secret>; the secret may contain underscores itself
+	parts := strings.SplitN(strings.TrimPrefix(key, domain.APIKeyPrefix), "_", 2)
+	if !strings.HasPrefix(key, domain.APIKeyPrefix) || len(parts) != 2 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	stored, err := s.apiKeyRepository.GetAPIKeyByPrefix(ctx, parts[0])
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(key)), []byte(stored.KeyHash)) != 1 || !stored.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	return stored.Principal(), nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type AuditService struct {
+	auditRepository ports.AuditRepositoryInterface
+}
+
+func NewAuditService(auditRepository ports.AuditRepositoryInterface) ports.AuditService {
+	return &AuditService{auditRepository: auditRepository}
+}
+
+func (a AuditService) ListAuditEvents(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidQuery)
+	}
+	if query.Limit <= 0 {
+		query.Limit = domain.DefaultAuditPageLimit
+	}
+	if query.Limit > domain.MaxAuditPageLimit {
+		query.Limit = domain.MaxAuditPageLimit
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidQuery)
+	}
+
+	return a.auditRepository.ListAuditEvents(ctx, query)
+}
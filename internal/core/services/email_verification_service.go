@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"net/url"
+	"time"
+)
+
+type EmailVerificationConfig struct {
+	Secret   []byte
+	TokenTTL time.Duration
+	// VerifyURL is where the link in the email points; the token is added as ?token=.
+	VerifyURL string
+}
+
+type EmailVerificationService struct {
+	userRepository ports.UserRepositoryInterface
+	notifier       ports.Notifier
+	tokens         purposeSigner
+	config         EmailVerificationConfig
+}
+
+func NewEmailVerificationService(userRepository ports.UserRepositoryInterface, notifier ports.Notifier, config EmailVerificationConfig) ports.EmailVerificationService {
+	return &EmailVerificationService{
+		userRepository: userRepository,
+		notifier:       notifier,
+		tokens:         newPurposeSigner(config.Secret, "email_verification"),
+		config:         config,
+	}
+}
+
+type verificationClaims struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (e EmailVerificationService) SendVerificationEmail(ctx context.Context, user *domain.User) error {
+	token, err := e.tokens.Sign(verificationClaims{
+		UserID:    user.ID.Hex(),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(e.config.TokenTTL).Unix(),
+	})
+	if err != nil {
+		return domain.ErrTokenGeneration
+	}
+
+	link := fmt.Sprintf("%s?token=%s", e.config.VerifyURL, url.QueryEscape(token))
+	return e.notifier.Notify(ctx, domain.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Confirm your email address at %s\nThe link expires in %s.", link, e.config.TokenTTL),
+	})
+}
+
+func (e EmailVerificationService) ResendVerificationEmail(ctx context.Context, id string) error {
+	user, err := e.userRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return e.SendVerificationEmail(ctx, user)
+}
+
+func (e EmailVerificationService) RequestVerificationEmail(ctx context.Context, email string) error {
+	var v domain.ValidationError
+	validateEmail(&v, email)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	user, err := e.userRepository.GetUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		log.Printf("Verification email requested for unknown email %s", email)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return e.SendVerificationEmail(ctx, user)
+}
+
+func (e EmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	var claims verificationClaims
+	if err := e.tokens.Verify(token, &claims); err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	err := e.userRepository.MarkUserEmailVerified(ctx, claims.UserID, claims.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.ErrInvalidToken
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"strings"
+	"time"
+)
+
+type MFAConfig struct {
+	// Issuer names the account in authenticator apps.
+	Issuer            string
+	RecoveryCodeCount int
+}
+
+type MFAService struct {
+	userRepository  ports.UserRepositoryInterface
+	mfaRepository   ports.MFARepositoryInterface
+	credentialGuard ports.CredentialGuard
+	config          MFAConfig
+}
+
+// NewMFAService counts wrong codes as failed logins through credentialGuard, when it is not nil.
+func NewMFAService(userRepository ports.UserRepositoryInterface, mfaRepository ports.MFARepositoryInterface, credentialGuard ports.CredentialGuard, config MFAConfig) ports.MFAService {
+	return &MFAService{userRepository: userRepository, mfaRepository: mfaRepository, credentialGuard: credentialGuard, config: config}
+}
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (m MFAService) EnrollTOTP(ctx context.Context, userID string) (*ports.TOTPEnrollment, error) {
+	if err := requireOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+	user, err := m.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := m.mfaRepository.GetMFA(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+	if current != nil && current.IsEnabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	// 160 bits, the key size RFC 4226 recommends for HMAC-SHA1
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	secret := totpEncoding.EncodeToString(key)
+	err = m.mfaRepository.SaveMFA(ctx, &domain.MFA{UserID: user.ID, Secret: secret, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: domain.TOTPProvisioningURI(m.config.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (m MFAService) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	if err := requireOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+	mfa, err := m.mfaRepository.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if err := m.verifyCode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	// Read again to keep the step the code just used
+	if mfa, err = m.mfaRepository.GetMFA(ctx, userID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	mfa.EnabledAt = &now
+	return m.saveRecoveryCodes(ctx, mfa)
+}
+
+func (m MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	if err := requireOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+	mfa, err := m.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verifyCode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	// Read again, the code that was just checked may have been one of the old recovery codes
+	if mfa, err = m.enabledMFA(ctx, userID); err != nil {
+		return nil, err
+	}
+	return m.saveRecoveryCodes(ctx, mfa)
+}
+
+func (m MFAService) DisableMFA(ctx context.Context, userID string, code string) error {
+	if err := requireOwner(ctx, userID); err != nil {
+		return err
+	}
+	mfa, err := m.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := m.verifyCode(ctx, mfa, code); err != nil {
+		return err
+	}
+	return m.mfaRepository.DeleteMFA(ctx, userID)
+}
+
+func (m MFAService) ResetMFA(ctx context.Context, userID string) error {
+	principal := domain.PrincipalFrom(ctx)
+	if principal == nil {
+		return domain.ErrPermissionDenied
+	}
+	// A leaked key must not be able to strip the second factor of every account
+	if principal.APIKeyID != "" {
+		return domain.ErrAPIKeyNotAllowed
+	}
+	if !principal.HasPermission(domain.PermissionMFAReset) {
+		return domain.ErrPermissionDenied
+	}
+	if _, err := m.mfaRepository.GetMFA(ctx, userID); err != nil {
+		return err
+	}
+	return m.mfaRepository.DeleteMFA(ctx, userID)
+}
+
+func (m MFAService) enabledMFA(ctx context.Context, userID string) (*domain.MFA, error) {
+	mfa, err := m.mfaRepository.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	return mfa, nil
+}
+
+// verifyCode checks a code of the user in ctx, who is not logging in but may be holding a stolen session.
+func (m MFAService) verifyCode(ctx context.Context, mfa *domain.MFA, code string) error {
+	if m.credentialGuard == nil {
+		return verifyMFACode(ctx, m.mfaRepository, mfa, code)
+	}
+	user, err := m.userRepository.GetUserByID(ctx, mfa.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	return m.credentialGuard.GuardCredential(ctx, user.Email, func() error {
+		return verifyMFACode(ctx, m.mfaRepository, mfa, code)
+	})
+}
+
+func (m MFAService) saveRecoveryCodes(ctx context.Context, mfa *domain.MFA) ([]string, error) {
+	codes := make([]string, 0, m.config.RecoveryCodeCount)
+	mfa.RecoveryCodes = make([]string, 0, m.config.RecoveryCodeCount)
+	for i := 0; i < m.config.RecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, domain.ErrTokenGeneration
+		}
+		code := hex.EncodeToString(buf)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		mfa.RecoveryCodes = append(mfa.RecoveryCodes, hashToken(code))
+	}
+	if err := m.mfaRepository.SaveMFA(ctx, mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// requireOwner keeps enrollment to the user themselves, an admin cannot hold someone else's second factor.
+func requireOwner(ctx context.Context, userID string) error {
+	principal := domain.PrincipalFrom(ctx)
+	if principal == nil || principal.APIKeyID != "" || principal.UserID != userID {
+		return domain.ErrNotOwner
+	}
+	return nil
+}
+
+// verifyMFACode accepts a TOTP code from the previous, current or next period, each period only once,
+// or any recovery code that has not been used yet.
+func verifyMFACode(ctx context.Context, mfaRepository ports.MFARepositoryInterface, mfa *domain.MFA, code string) error {
+	code = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+	userID := mfa.UserID.Hex()
+
+	if len(code) != domain.TOTPDigits || strings.Trim(code, "0123456789") != "" {
+		used, err := mfaRepository.UseRecoveryCode(ctx, userID, hashToken(code))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	key, err := totpEncoding.DecodeString(mfa.Secret)
+	if err != nil {
+		return err
+	}
+	current := domain.TOTPStep(time.Now())
+	for step := current - 1; step <= current+1; step++ {
+		if subtle.ConstantTimeCompare([]byte(domain.TOTPCode(key, step)), []byte(code)) != 1 {
+			continue
+		}
+		// A replayed code matches too, only the first use of a step counts
+		used, err := mfaRepository.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+	return domain.ErrInvalidMFACode
+}
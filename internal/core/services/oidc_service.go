@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"strings"
+	"time"
+)
+
+type OIDCConfig struct {
+	// SessionSecret signs the login session that travels with the browser between login and callback.
+	SessionSecret []byte
+	SessionTTL    time.Duration
+}
+
+type OIDCService struct {
+	userRepository     ports.UserRepositoryInterface
+	identityRepository ports.IdentityRepositoryInterface
+	tokenIssuer        ports.TokenIssuer
+	providers          map[string]ports.IdentityProvider
+	sessions           purposeSigner
+	config             OIDCConfig
+}
+
+func NewOIDCService(userRepository ports.UserRepositoryInterface, identityRepository ports.IdentityRepositoryInterface, tokenIssuer ports.TokenIssuer, providers map[string]ports.IdentityProvider, config OIDCConfig) ports.OIDCService {
+	return &OIDCService{
+		userRepository:     userRepository,
+		identityRepository: identityRepository,
+		tokenIssuer:        tokenIssuer,
+		providers:          providers,
+		sessions:           newPurposeSigner(config.SessionSecret, "oidc_session"),
+		config:             config,
+	}
+}
+
+// oidcSession travels signed with the browser; the nonce and verifier never leave it except towards the provider.
+type oidcSession struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	ExpiresAt    int64  `json:"exp"`
+}
+
+func (o OIDCService) StartLogin(ctx context.Context, provider string) (*ports.OIDCAuthorization, error) {
+	identityProvider, ok := o.providers[provider]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	session := oidcSession{Provider: provider, ExpiresAt: time.Now().Add(o.config.SessionTTL).Unix()}
+	for _, value := range []*string{&session.State, &session.Nonce, &session.CodeVerifier} {
+		random, err := newOpaqueToken()
+		if err != nil {
+			return nil, domain.ErrTokenGeneration
+		}
+		*value = random
+	}
+	signed, err := o.sessions.Sign(session)
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+
+	return &ports.OIDCAuthorization{
+		URL:     identityProvider.AuthCodeURL(session.State, session.Nonce, session.CodeVerifier),
+		Session: signed,
+	}, nil
+}
+
+func (o OIDCService) CompleteLogin(ctx context.Context, provider string, session string, state string, code string) (*ports.TokenPair, error) {
+	identityProvider, ok := o.providers[provider]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	// The state ties the callback to the browser that started the login, so nobody can log a victim into their account
+	var claims oidcSession
+	if err := o.sessions.Verify(session, &claims); err != nil || claims.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, domain.ErrInvalidOIDCState
+	}
+	if code == "" {
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	profile, err := identityProvider.Exchange(ctx, code, claims.CodeVerifier, claims.Nonce)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", provider, err)
+		return nil, domain.ErrOIDCLoginFailed
+	}
+	profile.Provider = provider
+
+	user, err := o.linkedUser(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	return o.tokenIssuer.IssueTokens(ctx, user)
+}
+
+// linkedUser finds the user of an external identity, linking it on its first login: to the account
+// with the same email when the provider vouches for that email, otherwise to a new account.
+func (o OIDCService) linkedUser(ctx context.Context, profile *domain.ExternalProfile) (*domain.User, error) {
+	identity, err := o.identityRepository.GetIdentity(ctx, profile.Provider, profile.Subject)
+	switch {
+	case err == nil:
+		user, err := o.userRepository.GetUserByID(ctx, identity.UserID.Hex())
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return user, err
+		}
+		// The user was deleted since, start over as if the identity was new
+		if err := o.identityRepository.DeleteIdentity(ctx, profile.Provider, profile.Subject); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, domain.ErrIdentityNotFound):
+		return nil, err
+	}
+
+	if profile.Email == "" {
+		return nil, domain.ErrOIDCLoginFailed
+	}
+	user, err := o.userRepository.GetUserByEmail(ctx, profile.Email)
+	switch {
+	case err == nil && !profile.EmailVerified:
+		// Linking on an unverified email would hand the account to whoever typed it in at the provider
+		return nil, domain.ErrEmailAlreadyExists
+	case errors.Is(err, domain.ErrUserNotFound):
+		if user, err = o.createUser(ctx, profile); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+
+	err = o.identityRepository.CreateIdentity(ctx, &domain.Identity{
+		UserID:    user.ID,
+		Provider:  profile.Provider,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser gives the account a random password nobody knows; the password reset flow can set a real one.
+func (o OIDCService) createUser(ctx context.Context, profile *domain.ExternalProfile) (*domain.User, error) {
+	password, err := newOpaqueToken()
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	name := strings.TrimSpace(profile.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(profile.Email, "@")
+	}
+
+	user := &domain.User{
+		Name:          name,
+		Email:         profile.Email,
+		Password:      password,
+		Roles:         []string{domain.RoleUser},
+		EmailVerified: profile.EmailVerified,
+	}
+	if err := o.userRepository.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"net/url"
+	"time"
+)
+
+type PasswordConfig struct {
+	Policy        domain.PasswordPolicy
+	ResetTokenTTL time.Duration
+	// ResetURL is the frontend page the reset link points at; the token is added as ?token=.
+	ResetURL string
+}
+
+type PasswordService struct {
+	userRepository       ports.UserRepositoryInterface
+	resetTokenRepository ports.PasswordResetTokenRepositoryInterface
+	notifier             ports.Notifier
+	tokenRevoker         ports.TokenRevoker
+	credentialGuard      ports.CredentialGuard
+	config               PasswordConfig
+}
+
+// NewPasswordService ends the user's sessions through tokenRevoker after a password change or reset, when it is not nil;
+// a user changing their own password stays logged in where they changed it. Wrong current passwords count as failed
+// logins through credentialGuard, when it is not nil.
+func NewPasswordService(userRepository ports.UserRepositoryInterface, resetTokenRepository ports.PasswordResetTokenRepositoryInterface, notifier ports.Notifier, tokenRevoker ports.TokenRevoker, credentialGuard ports.CredentialGuard, config PasswordConfig) ports.PasswordService {
+	return &PasswordService{
+		userRepository:       userRepository,
+		resetTokenRepository: resetTokenRepository,
+		notifier:             notifier,
+		tokenRevoker:         tokenRevoker,
+		credentialGuard:      credentialGuard,
+		config:               config,
+	}
+}
+
+func (p PasswordService) ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error {
+	var v domain.ValidationError
+	validateRequired(&v, "current_password", currentPassword)
+	validatePassword(&v, "new_password", newPassword, p.config.Policy)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	user, err := p.userRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	login, err := p.userRepository.GetUserLoginByEmail(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	err = p.guardCredential(ctx, user.Email, func() error {
+		// Not ErrInvalidCredentials: the caller is logged in, only the confirmation failed
+		if err := bcrypt.CompareHashAndPassword([]byte(login.Password), []byte(currentPassword)); err != nil {
+			return domain.ErrIncorrectPassword
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := p.userRepository.UpdateUserPassword(ctx, id, newPassword); err != nil {
+		return err
+	}
+	// An admin changing someone else's password has no session among theirs to keep
+	principal := domain.PrincipalFrom(ctx)
+	if p.tokenRevoker != nil && principal != nil && principal.APIKeyID == "" && principal.UserID == user.ID.Hex() {
+		return p.tokenRevoker.RevokeUserTokensExcept(ctx, id, principal.SessionID)
+	}
+	return p.revokeUserTokens(ctx, id)
+}
+
+func (p PasswordService) RequestPasswordReset(ctx context.Context, email string) error {
+	var v domain.ValidationError
+	validateEmail(&v, email)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	user, err := p.userRepository.GetUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		log.Printf("Password reset requested for unknown email %s", email)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return domain.ErrTokenGeneration
+	}
+	now := time.Now()
+	err = p.resetTokenRepository.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(p.config.ResetTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your password reset token is %s\nIt expires in %s.", token, p.config.ResetTokenTTL)
+	if p.config.ResetURL != "" {
+		body = fmt.Sprintf("Reset your password at %s?token=%s\nThe link expires in %s.", p.config.ResetURL, url.QueryEscape(token), p.config.ResetTokenTTL)
+	}
+	return p.notifier.Notify(ctx, domain.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+}
+
+func (p PasswordService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	var v domain.ValidationError
+	validateRequired(&v, "token", token)
+	validatePassword(&v, "password", newPassword, p.config.Policy)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	stored, err := p.resetTokenRepository.GetPasswordResetTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+
+	// Claim the token before touching the password, so it cannot be spent twice
+	used, err := p.resetTokenRepository.MarkPasswordResetTokenUsed(ctx, stored.ID.Hex(), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidToken
+	}
+
+	err = p.userRepository.UpdateUserPassword(ctx, stored.UserID.Hex(), newPassword)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	return p.revokeUserTokens(ctx, stored.UserID.Hex())
+}
+
+func (p PasswordService) guardCredential(ctx context.Context, email string, verify func() error) error {
+	if p.credentialGuard == nil {
+		return verify()
+	}
+	return p.credentialGuard.GuardCredential(ctx, email, verify)
+}
+
+func (p PasswordService) revokeUserTokens(ctx context.Context, id string) error {
+	if p.tokenRevoker == nil {
+		return nil
+	}
+	return p.tokenRevoker.RevokeUserTokens(ctx, id)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var errInvalidSignedToken = errors.New("invalid signed token")
+
+// purposeSigner signs the short-lived tokens that are deliberately not JWTs, so none of them can
+// pass as an access token: email verification links, MFA challenges and OIDC login sessions.
+// The purpose goes under the MAC, so the same secret signing something else cannot produce a valid token.
+type purposeSigner struct {
+	secret  []byte
+	purpose string
+}
+
+func newPurposeSigner(secret []byte, purpose string) purposeSigner {
+	return purposeSigner{secret: secret, purpose: purpose + "."}
+}
+
+// expiry is the part every signed payload shares; a payload without exp has always expired.
+type expiry struct {
+	ExpiresAt int64 `json:"exp"`
+}
+
+// Sign encodes claims as JSON, which must carry an exp in Unix seconds, and appends their MAC.
+func (s purposeSigner) Sign(claims any) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Verify decodes a token signed for this purpose into claims and fails on a bad MAC or once it expired.
+func (s purposeSigner) Verify(token string, claims any) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return errInvalidSignedToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, s.mac(encoded)) {
+		return errInvalidSignedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return errInvalidSignedToken
+	}
+	var exp expiry
+	if err := json.Unmarshal(payload, &exp); err != nil || time.Now().Unix() > exp.ExpiresAt {
+		return errInvalidSignedToken
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return errInvalidSignedToken
+	}
+	return nil
+}
+
+func (s purposeSigner) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(s.purpose + encoded))
+	return h.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"time"
+)
+
+type SessionConfig struct {
+	// AccessTokenTTL bounds how long a revoked session has to be remembered by the revocation list.
+	AccessTokenTTL time.Duration
+}
+
+type SessionService struct {
+	sessionRepository      ports.SessionRepositoryInterface
+	refreshTokenRepository ports.RefreshTokenRepositoryInterface
+	revocationRepository   ports.TokenRevocationRepositoryInterface
+	config                 SessionConfig
+}
+
+func NewSessionService(sessionRepository ports.SessionRepositoryInterface, refreshTokenRepository ports.RefreshTokenRepositoryInterface, revocationRepository ports.TokenRevocationRepositoryInterface, config SessionConfig) ports.SessionService {
+	return &SessionService{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationRepository:   revocationRepository,
+		config:                 config,
+	}
+}
+
+func (s SessionService) ListSessions(ctx context.Context, userID string) ([]domain.Session, error) {
+	if _, err := domain.ParseID(userID); err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionRepository.ListUserSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if principal := domain.PrincipalFrom(ctx); principal != nil && principal.SessionID != "" {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID == principal.SessionID
+		}
+	}
+	return sessions, nil
+}
+
+func (s SessionService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.sessionRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Someone else's session is reported as missing rather than confirmed to exist
+	if session.UserID.Hex() != userID || session.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+	return s.revoke(ctx, []string{session.ID}, time.Now())
+}
+
+func (s SessionService) RevokeOtherSessions(ctx context.Context, userID string) error {
+	objectID, err := domain.ParseID(userID)
+	if err != nil {
+		return err
+	}
+	// An admin ending a user's sessions has no session among them to keep
+	var keep string
+	if principal := domain.PrincipalFrom(ctx); principal != nil && principal.UserID == objectID.Hex() {
+		keep = principal.SessionID
+	}
+
+	now := time.Now()
+	revoked, err := s.sessionRepository.RevokeUserSessions(ctx, objectID.Hex(), keep, now)
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, revoked, now)
+}
+
+// revoke ends the refresh token families of the sessions and refuses their access tokens until they expire.
+func (s SessionService) revoke(ctx context.Context, sessionIDs []string, now time.Time) error {
+	for _, id := range sessionIDs {
+		if err := s.sessionRepository.RevokeSession(ctx, id, now); err != nil {
+			return err
+		}
+		if err := s.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, id, now); err != nil {
+			return err
+		}
+		if s.revocationRepository == nil {
+			continue
+		}
+		if err := s.revocationRepository.RevokeSession(ctx, id, now.Add(s.config.AccessTokenTTL)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
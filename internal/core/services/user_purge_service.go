@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"time"
+)
+
+type UserPurgeService struct {
+	userRepository               ports.UserRepositoryInterface
+	identityRepository           ports.IdentityRepositoryInterface
+	mfaRepository                ports.MFARepositoryInterface
+	sessionRepository            ports.SessionRepositoryInterface
+	refreshTokenRepository       ports.RefreshTokenRepositoryInterface
+	passwordResetTokenRepository ports.PasswordResetTokenRepositoryInterface
+	loginAttemptRepository       ports.LoginAttemptRepositoryInterface
+}
+
+func NewUserPurgeService(userRepository ports.UserRepositoryInterface, identityRepository ports.IdentityRepositoryInterface, mfaRepository ports.MFARepositoryInterface, sessionRepository ports.SessionRepositoryInterface, refreshTokenRepository ports.RefreshTokenRepositoryInterface, passwordResetTokenRepository ports.PasswordResetTokenRepositoryInterface, loginAttemptRepository ports.LoginAttemptRepositoryInterface) ports.UserPurgeService {
+	return &UserPurgeService{
+		userRepository:               userRepository,
+		identityRepository:           identityRepository,
+		mfaRepository:                mfaRepository,
+		sessionRepository:            sessionRepository,
+		refreshTokenRepository:       refreshTokenRepository,
+		passwordResetTokenRepository: passwordResetTokenRepository,
+		loginAttemptRepository:       loginAttemptRepository,
+	}
+}
+
+func (p UserPurgeService) GetPurgeableUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error) {
+	return p.userRepository.GetDeletedUsers(ctx, deletedBefore)
+}
+
+// PurgeUser removes the user's data before the user, so a purge that fails half way is simply
+// picked up again by the next run. A restore racing the purge keeps the user but may lose that data.
+func (p UserPurgeService) PurgeUser(ctx context.Context, user domain.User, deletedBefore time.Time) error {
+	id := user.ID.Hex()
+	cleanups := []func() error{
+		func() error { return p.identityRepository.DeleteUserIdentities(ctx, id) },
+		func() error { return p.mfaRepository.DeleteMFA(ctx, id) },
+		func() error { return p.sessionRepository.DeleteUserSessions(ctx, id) },
+		func() error { return p.refreshTokenRepository.DeleteUserRefreshTokens(ctx, id) },
+		func() error { return p.passwordResetTokenRepository.DeleteUserPasswordResetTokens(ctx, id) },
+		func() error { return p.resetLoginAttempts(ctx, user.Email) },
+	}
+	for _, cleanup := range cleanups {
+		if err := cleanup(); err != nil {
+			return err
+		}
+	}
+	return p.userRepository.PurgeUserByID(ctx, id, deletedBefore)
+}
+
+// resetLoginAttempts leaves the failures alone once the email belongs to a new account.
+func (p UserPurgeService) resetLoginAttempts(ctx context.Context, email string) error {
+	_, err := p.userRepository.GetUserByEmail(ctx, email)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	return p.loginAttemptRepository.ResetLoginAttempts(ctx, accountKey(email))
+}
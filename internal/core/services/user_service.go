@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"slices"
+)
+
+type UserConfig struct {
+	// AdminEmails are granted the admin role when they register, to bootstrap the first administrators.
+	AdminEmails    []string
+	PasswordPolicy domain.PasswordPolicy
+}
+
+type UserService struct {
+	userRepository    ports.UserRepositoryInterface
+	emailVerification ports.EmailVerificationService
+	tokenRevoker      ports.TokenRevoker
+	config            UserConfig
+}
+
+// NewUserService logs a deleted user out everywhere through tokenRevoker, when it is not nil.
+func NewUserService(userRepository ports.UserRepositoryInterface, emailVerification ports.EmailVerificationService, tokenRevoker ports.TokenRevoker, config UserConfig) ports.UserService {
+	return &UserService{userRepository: userRepository, emailVerification: emailVerification, tokenRevoker: tokenRevoker, config: config}
+}
+
+func (u UserService) RegisterUser(ctx context.Context, input ports.RegisterUserInput) (*domain.User, error) {
+	var v domain.ValidationError
+	validateName(&v, input.Name)
+	validateEmail(&v, input.Email)
+	validatePassword(&v, "password", input.Password, u.config.PasswordPolicy)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Check if the email already exists
+	existingUser, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	// Proceed to create the user
+	user := domain.User{Name: input.Name, Email: input.Email, Password: input.Password, Roles: []string{domain.RoleUser}}
+	if slices.Contains(u.config.AdminEmails, input.Email) {
+		user.Roles = append(user.Roles, domain.RoleAdmin)
+	}
+	if err := u.userRepository.CreateUser(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	u.sendVerificationEmail(ctx, &user)
+	return &user, nil
+}
+
+func (u UserService) GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidQuery)
+	}
+	if query.Limit <= 0 {
+		query.Limit = domain.DefaultUserPageLimit
+	}
+	if query.Limit > domain.MaxUserPageLimit {
+		query.Limit = domain.MaxUserPageLimit
+	}
+
+	switch query.SortBy {
+	case "":
+		query.SortBy = domain.SortByCreatedAt
+	case domain.SortByName, domain.SortByEmail, domain.SortByCreatedAt:
+	default:
+		return nil, fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidQuery, query.SortBy)
+	}
+
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		return nil, fmt.Errorf("%w: created_after must be before created_before", domain.ErrInvalidQuery)
+	}
+
+	return u.userRepository.GetAllUsers(ctx, query)
+}
+
+func (u UserService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return u.userRepository.GetUserByID(ctx, id)
+}
+
+func (u UserService) UpdateUserByID(ctx context.Context, id string, input ports.UpdateUserInput) (*domain.User, error) {
+	var v domain.ValidationError
+	if input.Name != nil {
+		validateName(&v, *input.Name)
+	}
+	if input.Email != nil {
+		validateEmail(&v, *input.Email)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	current, err := u.userRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	version := domain.AnyVersion
+	if input.Version != nil {
+		if *input.Version != current.Version {
+			return nil, domain.ErrVersionMismatch
+		}
+		version = *input.Version
+	}
+
+	// Only name and email are whitelisted; a password is never changed through here
+	update := domain.UserUpdate{Name: input.Name, Email: input.Email}
+	// A new address has to be verified again
+	emailChanged := input.Email != nil && current.Email != *input.Email
+	if emailChanged {
+		verified := false
+		update.EmailVerified = &verified
+	}
+
+	// The repository checks the version again, so a write that raced the read above still fails
+	// unless the caller asked for any version
+	user, err := u.userRepository.UpdateUserByID(ctx, id, version, update)
+	if err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		u.sendVerificationEmail(ctx, user)
+	}
+	return user, nil
+}
+
+func (u UserService) DeleteUserByID(ctx context.Context, id string) error {
+	if err := u.userRepository.DeleteUserByID(ctx, id); err != nil {
+		return err
+	}
+	if u.tokenRevoker == nil {
+		return nil
+	}
+	return u.tokenRevoker.RevokeUserTokens(ctx, id)
+}
+
+func (u UserService) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return u.userRepository.RestoreUserByID(ctx, id)
+}
+
+func (u UserService) GrantRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	if !domain.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	return u.userRepository.AddUserRole(ctx, id, role)
+}
+
+func (u UserService) RevokeRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	if !domain.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	return u.userRepository.RemoveUserRole(ctx, id, role)
+}
+
+// sendVerificationEmail does not fail the calling operation, the user can ask for another email.
+func (u UserService) sendVerificationEmail(ctx context.Context, user *domain.User) {
+	if u.emailVerification == nil {
+		return
+	}
+	if err := u.emailVerification.SendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"golang-rest/internal/core/domain"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxNameLength  = 100
+	maxEmailLength = 254
+)
+
+func validateName(v *domain.ValidationError, name string) {
+	switch length := utf8.RuneCountInString(strings.TrimSpace(name)); {
+	case length == 0:
+		v.Add("name", domain.ValidationRequired, "is required")
+	case length > maxNameLength:
+		v.Add("name", domain.ValidationTooLong, "must be at most 100 characters")
+	}
+}
+
+// validateEmail wants a bare address, so "Bob <bob@example.com>" is refused even though net/mail parses it.
+func validateEmail(v *domain.ValidationError, email string) {
+	if email == "" {
+		v.Add("email", domain.ValidationRequired, "is required")
+		return
+	}
+	if len(email) > maxEmailLength {
+		v.Add("email", domain.ValidationTooLong, "must be at most 254 characters")
+		return
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		v.Add("email", domain.ValidationInvalidEmail, "must be a valid email address")
+	}
+}
+
+func validatePassword(v *domain.ValidationError, field string, password string, policy domain.PasswordPolicy) {
+	if password == "" {
+		v.Add(field, domain.ValidationRequired, "is required")
+		return
+	}
+	if code, message := policy.Check(password); code != "" {
+		v.Add(field, code, message)
+	}
+}
+
+func validateRequired(v *domain.ValidationError, field string, value string) {
+	if value == "" {
+		v.Add(field, domain.ValidationRequired, "is required")
+	}
+}
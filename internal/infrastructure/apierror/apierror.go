@@ -0,0 +1,95 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"google.golang.org/grpc/codes"
+	"net/http"
+	"time"
+)
+
+// Problem is an error resolved to what every transport needs to answer with it.
+type Problem struct {
+	HTTPStatus int
+	GRPCCode   codes.Code
+	Code       string
+	Message    string
+	Fields     []domain.FieldError
+	// RetryAfter is set when the error says when to try again, see domain.RetryError.
+	RetryAfter time.Duration
+}
+
+// Envelope is the body of every HTTP error response.
+type Envelope struct {
+	Error     string              `json:"error"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Fields    []domain.FieldError `json:"fields,omitempty"`
+}
+
+var kinds = []struct {
+	kind       error
+	httpStatus int
+	grpcCode   codes.Code
+}{
+	{domain.ErrNotFound, http.StatusNotFound, codes.NotFound},
+	{domain.ErrConflict, http.StatusConflict, codes.AlreadyExists},
+	{domain.ErrInvalidID, http.StatusBadRequest, codes.InvalidArgument},
+	{domain.ErrInvalidInput, http.StatusBadRequest, codes.InvalidArgument},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, codes.Unauthenticated},
+	{domain.ErrForbidden, http.StatusForbidden, codes.PermissionDenied},
+	{domain.ErrRateLimited, http.StatusTooManyRequests, codes.ResourceExhausted},
+	{domain.ErrPreconditionFailed, http.StatusPreconditionFailed, codes.FailedPrecondition},
+	{domain.ErrPreconditionRequired, http.StatusPreconditionRequired, codes.FailedPrecondition},
+}
+
+// Resolve maps err by its domain kind. Anything unclassified, such as a database outage, becomes
+// an internal error that shows fallback instead of the underlying message.
+func Resolve(err error, fallback string) Problem {
+	if fallback == "" {
+		fallback = "internal server error"
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return Problem{
+			HTTPStatus: http.StatusBadRequest,
+			GRPCCode:   codes.InvalidArgument,
+			Code:       domain.ErrValidation.Code,
+			Message:    domain.ErrValidation.Message,
+			Fields:     validationErr.Fields,
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Problem{HTTPStatus: http.StatusGatewayTimeout, GRPCCode: codes.DeadlineExceeded, Code: "timeout", Message: "deadline exceeded"}
+	case errors.Is(err, context.Canceled):
+		return Problem{HTTPStatus: http.StatusServiceUnavailable, GRPCCode: codes.Canceled, Code: "canceled", Message: "request canceled"}
+	}
+
+	var retryAfter time.Duration
+	var retryErr *domain.RetryError
+	if errors.As(err, &retryErr) {
+		retryAfter = retryErr.RetryAfter
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		for _, k := range kinds {
+			if !errors.Is(err, k.kind) {
+				continue
+			}
+			// Input errors describe what the caller sent, so the wrapped detail is safe to show
+			message := domainErr.Message
+			if k.kind == domain.ErrInvalidInput {
+				message = err.Error()
+			}
+			return Problem{HTTPStatus: k.httpStatus, GRPCCode: k.grpcCode, Code: domainErr.Code, Message: message, RetryAfter: retryAfter}
+		}
+		return Problem{HTTPStatus: http.StatusInternalServerError, GRPCCode: codes.Internal, Code: domainErr.Code, Message: domainErr.Message}
+	}
+
+	return Problem{HTTPStatus: http.StatusInternalServerError, GRPCCode: codes.Internal, Code: "internal_error", Message: fallback}
+}
@@ -0,0 +1,44 @@
+package apierror
+
+import (
+	"context"
+	"golang-rest/internal/infrastructure/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"log"
+)
+
+const errorDomain = "golang-rest"
+
+// GRPCStatus turns err into a status error whose ErrorInfo carries the same code and request ID as the HTTP envelope.
+func GRPCStatus(ctx context.Context, err error) error {
+	problem := Resolve(err, "")
+	id := requestid.FromContext(ctx)
+	if problem.GRPCCode == codes.Internal {
+		log.Printf("[%s] gRPC call failed: %v", id, err)
+	}
+
+	st := status.New(problem.GRPCCode, problem.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   problem.Code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"request_id": id},
+	}}
+	if len(problem.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range problem.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Code + ": " + field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if withDetails, detailsErr := st.WithDetails(details...); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,29 @@
+package apierror
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/infrastructure/requestid"
+	"log"
+	"math"
+	"strconv"
+)
+
+// Respond writes err as an Envelope. fallback is the message for errors that are not classified.
+func Respond(ctx *fiber.Ctx, err error, fallback string) error {
+	problem := Resolve(err, fallback)
+	id := requestid.FromContext(ctx.UserContext())
+	if problem.HTTPStatus >= fiber.StatusInternalServerError {
+		log.Printf("[%s] %s %s failed: %v", id, ctx.Method(), ctx.Path(), err)
+	}
+
+	if problem.RetryAfter > 0 {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(problem.RetryAfter.Seconds()))))
+	}
+
+	return ctx.Status(problem.HTTPStatus).JSON(Envelope{
+		Error:     problem.Message,
+		Code:      problem.Code,
+		RequestID: id,
+		Fields:    problem.Fields,
+	})
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type apiKeyService struct {
+	ports.APIKeyService
+	recorder *Recorder
+}
+
+// NewAPIKeyService records keys being created and revoked; authenticating with one happens on
+// every request and is not recorded.
+func NewAPIKeyService(next ports.APIKeyService, recorder *Recorder) ports.APIKeyService {
+	return &apiKeyService{APIKeyService: next, recorder: recorder}
+}
+
+func (a apiKeyService) CreateAPIKey(ctx context.Context, input ports.CreateAPIKeyInput) (*ports.CreatedAPIKey, error) {
+	created, err := a.APIKeyService.CreateAPIKey(ctx, input)
+	event := domain.AuditEvent{Action: domain.AuditAPIKeyCreate}
+	if created != nil {
+		event.TargetID = created.APIKey.ID.Hex()
+	}
+	a.recorder.Record(ctx, event, err)
+	return created, err
+}
+
+func (a apiKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	err := a.APIKeyService.RevokeAPIKey(ctx, id)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditAPIKeyRevoke, TargetID: id}, err)
+	return err
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type authService struct {
+	ports.AuthService
+	recorder *Recorder
+}
+
+// NewAuthService records logins, whether they succeed, fail or wait for MFA, and the other
+// changes to a user's tokens. IssueTokens is only called by other services and passes through.
+func NewAuthService(next ports.AuthService, recorder *Recorder) ports.AuthService {
+	return &authService{AuthService: next, recorder: recorder}
+}
+
+func (a authService) LoginUser(ctx context.Context, email string, password string) (*ports.TokenPair, error) {
+	tokens, err := a.AuthService.LoginUser(ctx, email, password)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditLogin, TargetID: tokenSubject(tokens), TargetEmail: email}, err)
+	return tokens, err
+}
+
+func (a authService) VerifyMFA(ctx context.Context, mfaToken string, code string) (*ports.TokenPair, error) {
+	tokens, err := a.AuthService.VerifyMFA(ctx, mfaToken, code)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditLoginMFA, TargetID: tokenSubject(tokens)}, err)
+	return tokens, err
+}
+
+func (a authService) RefreshToken(ctx context.Context, refreshToken string) (*ports.TokenPair, error) {
+	tokens, err := a.AuthService.RefreshToken(ctx, refreshToken)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditTokenRefresh, TargetID: tokenSubject(tokens)}, err)
+	return tokens, err
+}
+
+func (a authService) Logout(ctx context.Context, refreshToken string) error {
+	err := a.AuthService.Logout(ctx, refreshToken)
+	event := domain.AuditEvent{Action: domain.AuditLogout}
+	if principal := domain.PrincipalFrom(ctx); principal != nil {
+		event.TargetID = principal.UserID
+	}
+	a.recorder.Record(ctx, event, err)
+	return err
+}
+
+func (a authService) UnlockUser(ctx context.Context, id string) error {
+	err := a.AuthService.UnlockUser(ctx, id)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditUnlock, TargetID: id}, err)
+	return err
+}
+
+func (a authService) RevokeUserTokens(ctx context.Context, id string) error {
+	err := a.AuthService.RevokeUserTokens(ctx, id)
+	a.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditRevokeTokens, TargetID: id}, err)
+	return err
+}
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type emailVerificationService struct {
+	ports.EmailVerificationService
+	recorder *Recorder
+}
+
+func NewEmailVerificationService(next ports.EmailVerificationService, recorder *Recorder) ports.EmailVerificationService {
+	return &emailVerificationService{EmailVerificationService: next, recorder: recorder}
+}
+
+func (e emailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	err := e.EmailVerificationService.VerifyEmail(ctx, token)
+	e.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditEmailVerify}, err)
+	return err
+}
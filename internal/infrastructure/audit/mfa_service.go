@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type mfaService struct {
+	ports.MFAService
+	recorder *Recorder
+}
+
+func NewMFAService(next ports.MFAService, recorder *Recorder) ports.MFAService {
+	return &mfaService{MFAService: next, recorder: recorder}
+}
+
+func (m mfaService) EnrollTOTP(ctx context.Context, userID string) (*ports.TOTPEnrollment, error) {
+	enrollment, err := m.MFAService.EnrollTOTP(ctx, userID)
+	m.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditMFAEnroll, TargetID: userID}, err)
+	return enrollment, err
+}
+
+func (m mfaService) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	codes, err := m.MFAService.ConfirmTOTP(ctx, userID, code)
+	m.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditMFAConfirm, TargetID: userID}, err)
+	return codes, err
+}
+
+func (m mfaService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	codes, err := m.MFAService.RegenerateRecoveryCodes(ctx, userID, code)
+	m.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditMFARecovery, TargetID: userID}, err)
+	return codes, err
+}
+
+func (m mfaService) DisableMFA(ctx context.Context, userID string, code string) error {
+	err := m.MFAService.DisableMFA(ctx, userID, code)
+	m.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditMFADisable, TargetID: userID}, err)
+	return err
+}
+
+func (m mfaService) ResetMFA(ctx context.Context, userID string) error {
+	err := m.MFAService.ResetMFA(ctx, userID)
+	m.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditMFAReset, TargetID: userID}, err)
+	return err
+}
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type oidcService struct {
+	ports.OIDCService
+	recorder *Recorder
+}
+
+func NewOIDCService(next ports.OIDCService, recorder *Recorder) ports.OIDCService {
+	return &oidcService{OIDCService: next, recorder: recorder}
+}
+
+func (o oidcService) CompleteLogin(ctx context.Context, provider string, session string, state string, code string) (*ports.TokenPair, error) {
+	tokens, err := o.OIDCService.CompleteLogin(ctx, provider, session, state, code)
+	o.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditLoginOIDC, TargetID: tokenSubject(tokens)}, err)
+	return tokens, err
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type passwordService struct {
+	ports.PasswordService
+	recorder *Recorder
+}
+
+func NewPasswordService(next ports.PasswordService, recorder *Recorder) ports.PasswordService {
+	return &passwordService{PasswordService: next, recorder: recorder}
+}
+
+func (p passwordService) ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error {
+	err := p.PasswordService.ChangePassword(ctx, id, currentPassword, newPassword)
+	p.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditPasswordChange, TargetID: id}, err)
+	return err
+}
+
+func (p passwordService) RequestPasswordReset(ctx context.Context, email string) error {
+	err := p.PasswordService.RequestPasswordReset(ctx, email)
+	p.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditPasswordForgot, TargetEmail: email}, err)
+	return err
+}
+
+// ResetPassword only has the token to go by, the event tells when and from where it was used.
+func (p passwordService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	err := p.PasswordService.ResetPassword(ctx, token, newPassword)
+	p.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditPasswordReset}, err)
+	return err
+}
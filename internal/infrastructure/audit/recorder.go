@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/internal/infrastructure/requestid"
+	"log"
+	"strings"
+	"time"
+)
+
+// Recorder fills audit events in from the request context and writes them. The services it
+// wraps sit at the transport edge, so calls from one service to another are not recorded twice.
+type Recorder struct {
+	repository ports.AuditRepositoryInterface
+}
+
+func NewRecorder(repository ports.AuditRepositoryInterface) *Recorder {
+	return &Recorder{repository: repository}
+}
+
+// Record notes the outcome of err. A failed write is only logged, the operation has already happened.
+func (r *Recorder) Record(ctx context.Context, event domain.AuditEvent, err error) {
+	var mfaRequired *domain.MFARequiredError
+	switch {
+	case err == nil:
+		event.Outcome = domain.AuditOutcomeSuccess
+	case errors.As(err, &mfaRequired):
+		event.Outcome = domain.AuditOutcomeMFA
+	default:
+		event.Outcome = domain.AuditOutcomeFailure
+		event.Reason = apierror.Resolve(err, "").Code
+	}
+
+	if principal := domain.PrincipalFrom(ctx); principal != nil {
+		if principal.APIKeyID != "" {
+			event.ActorID, event.ActorType = principal.APIKeyID, domain.AuditActorAPIKey
+		} else {
+			event.ActorID, event.ActorType = principal.UserID, domain.AuditActorUser
+		}
+	}
+	client := domain.ClientInfoFrom(ctx)
+	event.IP, event.UserAgent = client.IP, client.UserAgent
+	event.RequestID = requestid.FromContext(ctx)
+	// Targets often come straight from route parameters, which point into the request buffer
+	event.TargetID, event.TargetEmail = strings.Clone(event.TargetID), strings.Clone(event.TargetEmail)
+	event.CreatedAt = time.Now()
+
+	// A client hanging up right after the operation must not lose its record
+	if err := r.repository.RecordAuditEvent(context.WithoutCancel(ctx), &event); err != nil {
+		log.Printf("Failed to record audit event %s: %v", event.Action, err)
+	}
+}
+
+// tokenSubject reads the user id back from an access token this service has just issued.
+func tokenSubject(tokens *ports.TokenPair) string {
+	if tokens == nil {
+		return ""
+	}
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
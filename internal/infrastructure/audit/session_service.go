@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+)
+
+type sessionService struct {
+	ports.SessionService
+	recorder *Recorder
+}
+
+func NewSessionService(next ports.SessionService, recorder *Recorder) ports.SessionService {
+	return &sessionService{SessionService: next, recorder: recorder}
+}
+
+func (s sessionService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	err := s.SessionService.RevokeSession(ctx, userID, sessionID)
+	s.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditSessionRevoke, TargetID: userID}, err)
+	return err
+}
+
+func (s sessionService) RevokeOtherSessions(ctx context.Context, userID string) error {
+	err := s.SessionService.RevokeOtherSessions(ctx, userID)
+	s.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditSessionsRevoke, TargetID: userID}, err)
+	return err
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"time"
+)
+
+type userPurgeService struct {
+	ports.UserPurgeService
+	recorder *Recorder
+}
+
+// NewUserPurgeService records one event per purged user; the purge runs in the background, so the
+// events name the system as their actor. A user restored or purged by another instance meanwhile
+// was not purged here and is not recorded.
+func NewUserPurgeService(next ports.UserPurgeService, recorder *Recorder) ports.UserPurgeService {
+	return &userPurgeService{UserPurgeService: next, recorder: recorder}
+}
+
+func (u userPurgeService) PurgeUser(ctx context.Context, user domain.User, deletedBefore time.Time) error {
+	err := u.UserPurgeService.PurgeUser(ctx, user, deletedBefore)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	u.recorder.Record(ctx, domain.AuditEvent{
+		Action:      domain.AuditUserPurge,
+		ActorType:   domain.AuditActorSystem,
+		TargetID:    user.ID.Hex(),
+		TargetEmail: user.Email,
+	}, err)
+	return err
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"strconv"
+)
+
+type userService struct {
+	ports.UserService
+	recorder *Recorder
+}
+
+// NewUserService records every change to users, reads pass straight through.
+func NewUserService(next ports.UserService, recorder *Recorder) ports.UserService {
+	return &userService{UserService: next, recorder: recorder}
+}
+
+func (u userService) RegisterUser(ctx context.Context, input ports.RegisterUserInput) (*domain.User, error) {
+	user, err := u.UserService.RegisterUser(ctx, input)
+	event := domain.AuditEvent{Action: domain.AuditUserRegister, TargetEmail: input.Email}
+	if user != nil {
+		event.TargetID = user.ID.Hex()
+	}
+	u.recorder.Record(ctx, event, err)
+	return user, err
+}
+
+func (u userService) UpdateUserByID(ctx context.Context, id string, input ports.UpdateUserInput) (*domain.User, error) {
+	// A failed lookup fails the update the same way, which is recorded below
+	before, _ := u.UserService.GetUserByID(ctx, id)
+	user, err := u.UserService.UpdateUserByID(ctx, id, input)
+	event := domain.AuditEvent{Action: domain.AuditUserUpdate, TargetID: id}
+	// Another write in between would show up in the diff as well, so only diff the direct predecessor
+	if before != nil && user != nil && before.Version+1 == user.Version {
+		event.Changes = userChanges(before, user)
+	}
+	u.recorder.Record(ctx, event, err)
+	return user, err
+}
+
+func (u userService) DeleteUserByID(ctx context.Context, id string) error {
+	err := u.UserService.DeleteUserByID(ctx, id)
+	u.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditUserDelete, TargetID: id}, err)
+	return err
+}
+
+func (u userService) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	user, err := u.UserService.RestoreUserByID(ctx, id)
+	u.recorder.Record(ctx, domain.AuditEvent{Action: domain.AuditUserRestore, TargetID: id}, err)
+	return user, err
+}
+
+func (u userService) GrantRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	user, err := u.UserService.GrantRole(ctx, id, role)
+	u.recorder.Record(ctx, roleEvent(domain.AuditRoleGrant, id, role), err)
+	return user, err
+}
+
+func (u userService) RevokeRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	user, err := u.UserService.RevokeRole(ctx, id, role)
+	u.recorder.Record(ctx, roleEvent(domain.AuditRoleRevoke, id, role), err)
+	return user, err
+}
+
+func roleEvent(action string, id string, role string) domain.AuditEvent {
+	change := domain.AuditChange{To: role}
+	if action == domain.AuditRoleRevoke {
+		change = domain.AuditChange{From: role}
+	}
+	return domain.AuditEvent{Action: action, TargetID: id, Changes: map[string]domain.AuditChange{"role": change}}
+}
+
+func userChanges(before *domain.User, after *domain.User) map[string]domain.AuditChange {
+	changes := map[string]domain.AuditChange{}
+	for field, values := range map[string][2]string{
+		"name":           {before.Name, after.Name},
+		"email":          {before.Email, after.Email},
+		"email_verified": {strconv.FormatBool(before.EmailVerified), strconv.FormatBool(after.EmailVerified)},
+	} {
+		if values[0] != values[1] {
+			changes[field] = domain.AuditChange{From: values[0], To: values[1]}
+		}
+	}
+	return changes
+}
@@ -2,6 +2,7 @@ package background
 
 import (
 	"context"
+	"golang-rest/internal/core/domain"
 	"golang-rest/internal/core/ports"
 	"log"
 	"sync"
@@ -20,12 +21,13 @@ func StartUserLogger(ctx context.Context, wg *sync.WaitGroup, userRepository por
 				log.Println("User logger shutting down.")
 				return
 			case <-ticker.C:
-				users, err := userRepository.GetAllUsers()
+				// Only the total is needed, so fetch the smallest possible page
+				page, err := userRepository.GetAllUsers(ctx, domain.UserQuery{Limit: 1})
 				if err != nil {
 					log.Printf("Error fetching users: %v\n", err)
 					continue
 				}
-				log.Printf("Total users: %d\n", len(users))
+				log.Printf("Total users: %d\n", page.Total)
 			}
 		}
 	}()
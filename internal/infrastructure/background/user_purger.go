@@ -0,0 +1,56 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"log"
+	"sync"
+	"time"
+)
+
+// StartUserPurger removes the users deleted longer than retention ago, checking every interval.
+func StartUserPurger(ctx context.Context, wg *sync.WaitGroup, purgeService ports.UserPurgeService, retention time.Duration, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("User purger shutting down.")
+				return
+			case <-ticker.C:
+				if purged := PurgeDeletedUsers(ctx, purgeService, time.Now().Add(-retention)); purged > 0 {
+					log.Printf("Purged %d deleted users\n", purged)
+				}
+			}
+		}
+	}()
+}
+
+// PurgeDeletedUsers runs one purge and returns how many users it removed; a user that fails
+// is logged and left for the next run.
+func PurgeDeletedUsers(ctx context.Context, purgeService ports.UserPurgeService, deletedBefore time.Time) int {
+	users, err := purgeService.GetPurgeableUsers(ctx, deletedBefore)
+	if err != nil {
+		log.Printf("Error listing deleted users: %v\n", err)
+		return 0
+	}
+
+	purged := 0
+	for _, user := range users {
+		err := purgeService.PurgeUser(ctx, user, deletedBefore)
+		switch {
+		case err == nil:
+			purged++
+		case errors.Is(err, domain.ErrUserNotFound):
+			// Restored or purged by another instance meanwhile
+		default:
+			log.Printf("Error purging deleted user %s: %v\n", user.ID.Hex(), err)
+		}
+	}
+	return purged
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func GetEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetDuration parses values such as "5s" or "250ms", falling back on a missing or malformed value.
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value := GetEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %v, using %s", key, err, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// GetBool accepts the values strconv.ParseBool does, falling back on a missing or malformed value.
+func GetBool(key string, fallback bool) bool {
+	value := GetEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for %s: %v, using %t", key, err, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetList splits a comma separated value, dropping blank entries.
+func GetList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
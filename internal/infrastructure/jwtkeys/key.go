@@ -0,0 +1,206 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is one signing key pair; a Key without Private can only verify.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// JWK is the public half of a key as published in the JWKS document.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GenerateKey makes a new RS256 (2048 bit) or EdDSA (Ed25519) key.
+func GenerateKey(algorithm string) (Key, error) {
+	switch algorithm {
+	case RS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(private, private.Public())
+	case EdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(private, public)
+	default:
+		return Key{}, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+}
+
+// ParsePEM reads an RSA or Ed25519 key from PKCS#8, PKCS#1 or PKIX PEM.
+// A public key gives a Key that only verifies, for keys that are being rotated out.
+func ParsePEM(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return Key{}, fmt.Errorf("unsupported private key type %T", parsed)
+		}
+		return newKey(signer, signer.Public())
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(private, private.Public())
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(nil, public)
+	case "RSA PUBLIC KEY":
+		public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(nil, public)
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// MarshalPEM writes the private key as PKCS#8, or the public key as PKIX for a verify-only Key.
+func (k Key) MarshalPEM() ([]byte, error) {
+	if k.Private == nil {
+		der, err := x509.MarshalPKIXPublicKey(k.Public)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// JWK leaves out every private part of the key.
+func (k Key) JWK() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// Key turns a published JWK back into a verify-only Key; it keeps the kid of the JWK,
+// which other issuers do not have to derive from the thumbprint.
+func (j JWK) Key() (Key, error) {
+	var public crypto.PublicKey
+	switch j.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return Key{}, errors.New("invalid RSA exponent")
+		}
+		public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || j.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return Key{}, errors.New("invalid Ed25519 key")
+		}
+		public = ed25519.PublicKey(x)
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %q", j.KeyType)
+	}
+
+	key, err := newKey(nil, public)
+	if err != nil {
+		return Key{}, err
+	}
+	if j.KeyID != "" {
+		key.ID = j.KeyID
+	}
+	return key, nil
+}
+
+func newKey(private crypto.Signer, public crypto.PublicKey) (Key, error) {
+	key := Key{Private: private, Public: public}
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < 2048 {
+			return Key{}, fmt.Errorf("RSA key of %d bits is too small, use at least 2048", public.N.BitLen())
+		}
+		key.Algorithm = RS256
+	case ed25519.PublicKey:
+		key.Algorithm = EdDSA
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	// The kid is the RFC 7638 thumbprint, so every instance loading the same key agrees on it
+	key.ID = thumbprint(key.JWK())
+	return key, nil
+}
+
+// thumbprint hashes only the required members; json.Marshal sorts map keys the way RFC 7638 asks.
+func thumbprint(jwk JWK) string {
+	members := map[string]string{"kty": jwk.KeyType}
+	if jwk.KeyType == "RSA" {
+		members["e"], members["n"] = jwk.E, jwk.N
+	} else {
+		members["crv"], members["x"] = jwk.Curve, jwk.X
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
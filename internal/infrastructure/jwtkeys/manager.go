@@ -0,0 +1,115 @@
+package jwtkeys
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"golang-rest/internal/core/ports"
+	"slices"
+	"sync"
+)
+
+var (
+	errUnknownKey        = errors.New("token signed with an unknown key")
+	errAlgorithmMismatch = errors.New("token algorithm does not match its key")
+)
+
+// KeyManager signs with one key and verifies against every key it still holds,
+// so tokens signed before a rotation stay valid until they expire.
+type KeyManager struct {
+	mu      sync.RWMutex
+	signing Key
+	keys    map[string]Key
+	order   []string
+}
+
+// NewKeyManager signs with signing and also accepts tokens from the verify-only keys.
+func NewKeyManager(signing Key, verifyOnly ...Key) (*KeyManager, error) {
+	if signing.Private == nil {
+		return nil, errors.New("the signing key needs its private part")
+	}
+	manager := &KeyManager{keys: make(map[string]Key)}
+	for _, key := range slices.Concat(verifyOnly, []Key{signing}) {
+		manager.add(key)
+	}
+	manager.signing = signing
+	return manager, nil
+}
+
+var _ ports.TokenSigner = (*KeyManager)(nil)
+
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	signing := m.signing
+	m.mu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(signing.Algorithm), claims)
+	token.Header["kid"] = signing.ID
+	return token.SignedString(signing.Private)
+}
+
+// Parse verifies the token against the key named by its kid and fills in claims;
+// options add the claim checks on top.
+func (m *KeyManager) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods([]string{RS256, EdDSA}))
+	return jwt.ParseWithClaims(tokenString, claims, m.keyFor, options...)
+}
+
+func (m *KeyManager) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	m.mu.RLock()
+	key, ok := m.keys[kid]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, errUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errAlgorithmMismatch
+	}
+	return key.Public, nil
+}
+
+// Rotate makes key the signing key; the previous one keeps verifying until Retire drops it.
+func (m *KeyManager) Rotate(key Key) error {
+	if key.Private == nil {
+		return errors.New("the signing key needs its private part")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.add(key)
+	m.signing = key
+	return nil
+}
+
+func (m *KeyManager) Retire(kid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if kid == m.signing.ID {
+		return fmt.Errorf("key %s is still used for signing", kid)
+	}
+	delete(m.keys, kid)
+	m.order = slices.DeleteFunc(m.order, func(id string) bool { return id == kid })
+	return nil
+}
+
+// JWKS lists the public keys, newest first, for GET /.well-known/jwks.json.
+func (m *KeyManager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(m.order))}
+	for i := len(m.order) - 1; i >= 0; i-- {
+		jwks.Keys = append(jwks.Keys, m.keys[m.order[i]].JWK())
+	}
+	return jwks
+}
+
+func (m *KeyManager) add(key Key) {
+	if _, ok := m.keys[key.ID]; !ok {
+		m.order = append(m.order, key.ID)
+	}
+	m.keys[key.ID] = key
+}
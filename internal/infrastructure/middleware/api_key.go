@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/infrastructure/apierror"
+	"strings"
+)
+
+// APIKeyHeader is the alternative to `Authorization: ApiKey <key>`.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator turns an API key into its principal, see ports.APIKeyService.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error)
+}
+
+// APIKeyAuth lets a request in with an API key and puts its principal where Protected would, so a
+// Protected that runs next lets it through. Requests without an API key are left to Protected.
+func APIKeyAuth(authenticator APIKeyAuthenticator) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := APIKeyFrom(ctx.Get("Authorization"), ctx.Get(APIKeyHeader))
+		if key == "" {
+			return ctx.Next()
+		}
+
+		principal, err := authenticator.AuthenticateAPIKey(ctx.UserContext(), key)
+		if err != nil {
+			return apierror.Respond(ctx, err, "")
+		}
+		ctx.Locals(principalKey, principal)
+		ctx.SetUserContext(domain.WithPrincipal(ctx.UserContext(), principal))
+		return ctx.Next()
+	}
+}
+
+// APIKeyFrom picks the key out of an `Authorization: ApiKey <key>` header, or else the X-API-Key header.
+func APIKeyFrom(authorization string, apiKeyHeader string) string {
+	if scheme, value, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "apikey") {
+		return value
+	}
+	return apiKeyHeader
+}
@@ -1,48 +1,167 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"os"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/apierror"
+	"log"
 	"strings"
+	"time"
 )
 
-func Protected() fiber.Handler {
+const principalKey = "principal"
+
+// sessionSeenInterval spaces out the last-seen writes of a session that keeps using its token.
+const sessionSeenInterval = time.Minute
+
+var (
+	errMissingAuthorization = domain.NewError(domain.ErrUnauthorized, "missing_authorization", "missing Authorization header")
+	errInvalidAuthorization = domain.NewError(domain.ErrUnauthorized, "invalid_authorization", "invalid Authorization header format")
+)
+
+// TokenVerifier checks an access token's signature and fills in its claims, see jwtkeys.KeyManager.
+type TokenVerifier interface {
+	Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error)
+}
+
+// TokenValidation is what an access token has to match besides its signature.
+type TokenValidation struct {
+	Issuer   string
+	Audience string
+	// Leeway allows for clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// Protected lets a request through only with a valid bearer token that has not been revoked, and puts
+// the caller's domain.Principal into ctx.Locals and the user context. Like RequestID it must run after
+// RequestContext. Callers that APIKeyAuth already let in are passed through.
+func Protected(tokens TokenAuthenticator) fiber.Handler {
+	authenticate := newAuthenticator(tokens)
 	return func(ctx *fiber.Ctx) error {
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "JWT_SECRET is not set"})
+		if PrincipalFrom(ctx) != nil {
+			return ctx.Next()
 		}
-
-		authHeader := ctx.Get("Authorization")
-		if authHeader == "" {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing Authorization header"})
+		if err := authenticate(ctx); err != nil {
+			return apierror.Respond(ctx, err, "")
 		}
+		return ctx.Next()
+	}
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid Authorization header format"})
+// OptionalAuth is Protected for routes that also serve anonymous callers: without an Authorization
+// header the request goes through with no principal.
+func OptionalAuth(tokens TokenAuthenticator) fiber.Handler {
+	authenticate := newAuthenticator(tokens)
+	return func(ctx *fiber.Ctx) error {
+		if ctx.Get("Authorization") == "" {
+			return ctx.Next()
+		}
+		if err := authenticate(ctx); err != nil {
+			return apierror.Respond(ctx, err, "")
 		}
-		tokenString := parts[1]
+		return ctx.Next()
+	}
+}
+
+// TokenAuthenticator checks bearer tokens for any transport: Protected and OptionalAuth use it for HTTP,
+// the gRPC AuthInterceptor for calls. A nil revocations skips the revocation list. A nil sessions skips
+// the session check, which unlike the revocation list may hold across restarts and instances.
+type TokenAuthenticator struct {
+	verifier    TokenVerifier
+	options     []jwt.ParserOption
+	revocations ports.TokenRevocationRepositoryInterface
+	sessions    ports.SessionRepositoryInterface
+}
+
+func NewTokenAuthenticator(verifier TokenVerifier, validation TokenValidation, revocations ports.TokenRevocationRepositoryInterface, sessions ports.SessionRepositoryInterface) TokenAuthenticator {
+	options := []jwt.ParserOption{jwt.WithLeeway(validation.Leeway), jwt.WithIssuedAt(), jwt.WithExpirationRequired()}
+	if validation.Issuer != "" {
+		options = append(options, jwt.WithIssuer(validation.Issuer))
+	}
+	if validation.Audience != "" {
+		options = append(options, jwt.WithAudience(validation.Audience))
+	}
+	return TokenAuthenticator{verifier: verifier, options: options, revocations: revocations, sessions: sessions}
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		})
+// Authenticate takes the value of an Authorization header and returns the caller it names.
+func (a TokenAuthenticator) Authenticate(ctx context.Context, authorization string) (*domain.Principal, error) {
+	if authorization == "" {
+		return nil, errMissingAuthorization
+	}
+
+	parts := strings.Split(authorization, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, errInvalidAuthorization
+	}
+	tokenString := parts[1]
 
-		if err != nil || !token.Valid {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+	var claims ports.AccessTokenClaims
+	if _, err := a.verifier.Parse(tokenString, &claims, a.options...); err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if a.revocations != nil {
+		revoked, err := a.revocations.IsAccessTokenRevoked(ctx, claims.ID, claims.SessionID, claims.Subject, claims.IssuedAt.Time)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, domain.ErrTokenRevoked
 		}
+	}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userId, ok := claims["user_id"].(string); ok {
-				ctx.Locals("user_id", userId)
-			}
+	// Tokens from before sessions existed carry no sid
+	if a.sessions != nil && claims.SessionID != "" {
+		if err := a.checkSession(ctx, claims.SessionID); err != nil {
+			return nil, err
 		}
+	}
 
-		return ctx.Next()
+	return claims.Principal(), nil
+}
+
+// checkSession refuses the tokens of a session that was revoked or is gone, and records that it was seen.
+func (a TokenAuthenticator) checkSession(ctx context.Context, id string) error {
+	session, err := a.sessions.GetSession(ctx, id)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return domain.ErrTokenRevoked
+	}
+	if err != nil {
+		return err
 	}
+	if session.RevokedAt != nil {
+		return domain.ErrTokenRevoked
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= sessionSeenInterval {
+		// A failed write only makes the session listing less accurate
+		if err := a.sessions.MarkSessionSeen(ctx, id, now); err != nil {
+			log.Printf("Could not mark session %s seen: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func newAuthenticator(tokens TokenAuthenticator) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		principal, err := tokens.Authenticate(ctx.UserContext(), ctx.Get("Authorization"))
+		if err != nil {
+			return err
+		}
+		ctx.Locals(principalKey, principal)
+		ctx.SetUserContext(domain.WithPrincipal(ctx.UserContext(), principal))
+		return nil
+	}
+}
+
+// PrincipalFrom returns the caller that Protected let through, or nil before Protected ran.
+func PrincipalFrom(ctx *fiber.Ctx) *domain.Principal {
+	principal, _ := ctx.Locals(principalKey).(*domain.Principal)
+	return principal
 }
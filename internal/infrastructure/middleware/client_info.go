@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"strings"
+)
+
+// ClientInfo records the caller's IP and user agent for the services. Like RequestID it
+// must run after RequestContext. The IP honours fiber.Config.ProxyHeader when one is set.
+// Both are copied, services may keep them past the request.
+func ClientInfo() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		ctx.SetUserContext(domain.WithClientInfo(ctx.UserContext(), domain.ClientInfo{
+			IP:        strings.Clone(ctx.IP()),
+			UserAgent: strings.Clone(ctx.Get(fiber.HeaderUserAgent)),
+		}))
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestContext derives each request's user context from parent, so cancelling parent
+// on shutdown aborts the storage calls that are still in flight.
+func RequestContext(parent context.Context) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestCtx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		ctx.SetUserContext(requestCtx)
+		return ctx.Next()
+	}
+}
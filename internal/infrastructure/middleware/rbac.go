@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/infrastructure/apierror"
+)
+
+// RequirePermission must run after Protected, which puts the caller's principal into ctx.Locals.
+func RequirePermission(permission domain.Permission) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		principal := PrincipalFrom(ctx)
+		if principal == nil || !principal.HasPermission(permission) {
+			return apierror.Respond(ctx, domain.ErrPermissionDenied, "")
+		}
+
+		return ctx.Next()
+	}
+}
+
+// RequireOwnerOrPermission lets the caller through when the route parameter names their own user id,
+// otherwise they need the permission. Like RequirePermission it must run after Protected.
+func RequireOwnerOrPermission(param string, permission domain.Permission) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		principal := PrincipalFrom(ctx)
+		if principal == nil || !principal.CanAccessUser(ctx.Params(param), permission) {
+			return apierror.Respond(ctx, domain.ErrNotOwner, "")
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"golang-rest/internal/infrastructure/requestid"
+)
+
+// RequestID must run after RequestContext, which replaces the user context it stores the ID in.
+func RequestID() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		id := requestid.Resolve(ctx.Get(requestid.Header))
+		ctx.Set(requestid.Header, id)
+		ctx.SetUserContext(requestid.NewContext(ctx.UserContext(), id))
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,183 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/jwtkeys"
+	"golang.org/x/oauth2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// Issuer is the provider's issuer URL, its discovery document lives below it.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider is an OpenID Connect provider found through discovery. Its signing keys are fetched
+// from the jwks_uri and fetched again when a token names a kid we have not seen.
+type Provider struct {
+	issuer     string
+	clientID   string
+	jwksURL    string
+	oauth      oauth2.Config
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	keys map[string]jwtkeys.Key
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// Discover reads the provider's /.well-known/openid-configuration; a nil httpClient uses http.DefaultClient.
+func Discover(ctx context.Context, config Config, httpClient *http.Client) (ports.IdentityProvider, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	var document discoveryDocument
+	if err := getJSON(ctx, httpClient, strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", &document); err != nil {
+		return nil, fmt.Errorf("discover %s: %w", config.Issuer, err)
+	}
+	if document.Issuer != config.Issuer {
+		return nil, fmt.Errorf("discover %s: document is for issuer %q", config.Issuer, document.Issuer)
+	}
+	if document.AuthorizationEndpoint == "" || document.TokenEndpoint == "" || document.JWKSURI == "" {
+		return nil, fmt.Errorf("discover %s: incomplete discovery document", config.Issuer)
+	}
+
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{
+		issuer:   document.Issuer,
+		clientID: config.ClientID,
+		jwksURL:  document.JWKSURI,
+		oauth: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       scopes,
+			Endpoint:     oauth2.Endpoint{AuthURL: document.AuthorizationEndpoint, TokenURL: document.TokenEndpoint},
+		},
+		httpClient: httpClient,
+		keys:       make(map[string]jwtkeys.Key),
+	}, nil
+}
+
+func (p *Provider) AuthCodeURL(state string, nonce string, codeVerifier string) string {
+	return p.oauth.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce), oauth2.S256ChallengeOption(codeVerifier))
+}
+
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.ExternalProfile, error) {
+	token, err := p.oauth.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		return p.keyFor(ctx, token)
+	},
+		jwt.WithValidMethods([]string{jwtkeys.RS256, jwtkeys.EdDSA}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if claims.Subject == "" || claims.Nonce != nonce {
+		return nil, errors.New("id_token subject or nonce does not match")
+	}
+
+	return &domain.ExternalProfile{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *Provider) keyFor(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok = p.keys[kid]; !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("signing key %q is not for %s", kid, token.Method.Alg())
+	}
+	return key.Public, nil
+}
+
+// refreshKeys skips keys of types we cannot use instead of failing on them.
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	var jwks jwtkeys.JWKS
+	if err := getJSON(ctx, p.httpClient, p.jwksURL, &jwks); err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]jwtkeys.Key, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if key, err := jwk.Key(); err == nil {
+			keys[key.ID] = key
+		}
+	}
+	p.keys = keys
+	return nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	requests  []time.Time
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore keeps counters in process, so every instance of the API limits on its own.
+func NewMemoryStore() Store {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok || now.After(b.expiresAt) {
+		b = &bucket{tokens: float64(policy.Limit), updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.expiresAt = now.Add(policy.Window)
+
+	if policy.Algorithm == SlidingWindow {
+		return s.slidingWindow(b, policy, now), nil
+	}
+	return s.tokenBucket(b, policy, now), nil
+}
+
+func (s *MemoryStore) tokenBucket(b *bucket, policy Policy, now time.Time) Result {
+	elapsed := max(now.Sub(b.updatedAt), 0)
+	b.tokens = math.Min(float64(policy.Limit), b.tokens+float64(elapsed)*float64(policy.Limit)/float64(policy.Window))
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return tokenBucketResult(policy, allowed, b.tokens)
+}
+
+func (s *MemoryStore) slidingWindow(b *bucket, policy Policy, now time.Time) Result {
+	start := now.Add(-policy.Window)
+	kept := b.requests[:0]
+	for _, at := range b.requests {
+		if at.After(start) {
+			kept = append(kept, at)
+		}
+	}
+	b.requests = kept
+
+	allowed := len(b.requests) < policy.Limit
+	if allowed {
+		b.requests = append(b.requests, now)
+	}
+	oldest := now
+	if len(b.requests) > 0 {
+		oldest = b.requests[0]
+	}
+	return slidingWindowResult(policy, allowed, len(b.requests), oldest, now)
+}
+
+// sweep drops idle keys about once a minute, so one-off clients do not pile up.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.After(b.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
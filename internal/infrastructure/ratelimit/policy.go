@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	// TokenBucket allows bursts up to Limit and refills Limit tokens per Window.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows at most Limit requests in any Window long stretch of time.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+type Policy struct {
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// ParsePolicy reads policies written as "<algorithm>:<limit>/<window>", e.g. "token_bucket:100/1m".
+func ParsePolicy(value string) (Policy, error) {
+	algorithm, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit policy %q: want <algorithm>:<limit>/<window>", value)
+	}
+	limit, window, ok := strings.Cut(rest, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit policy %q: want <algorithm>:<limit>/<window>", value)
+	}
+
+	policy := Policy{Algorithm: Algorithm(algorithm)}
+	var err error
+	if policy.Limit, err = strconv.Atoi(limit); err != nil {
+		return Policy{}, fmt.Errorf("rate limit policy %q: %w", value, err)
+	}
+	if policy.Window, err = time.ParseDuration(window); err != nil {
+		return Policy{}, fmt.Errorf("rate limit policy %q: %w", value, err)
+	}
+	return policy, policy.Validate()
+}
+
+func (p Policy) Validate() error {
+	if p.Algorithm != TokenBucket && p.Algorithm != SlidingWindow {
+		return fmt.Errorf("unknown rate limit algorithm %q", p.Algorithm)
+	}
+	if p.Limit <= 0 || p.Window <= 0 {
+		return fmt.Errorf("rate limit needs a positive limit and window, got %d/%s", p.Limit, p.Window)
+	}
+	return nil
+}
+
+// Header formats the policy for the RateLimit-Policy response header.
+func (p Policy) Header() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(p.Window.Seconds()))
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the full quota is available again.
+	ResetAfter time.Duration
+	// RetryAfter is how long a refused caller has to wait for the next request to pass.
+	RetryAfter time.Duration
+}
+
+// Store keeps the counters; Allow has to check and count a request in one atomic step.
+type Store interface {
+	Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// tokenBucketResult turns the tokens left after a request into the caller facing numbers.
+func tokenBucketResult(policy Policy, allowed bool, tokens float64) Result {
+	perToken := policy.Window / time.Duration(policy.Limit)
+	result := Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(policy.Limit) - tokens) * float64(perToken)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return result
+}
+
+// slidingWindowResult works from the number of requests now in the window and the oldest of them.
+func slidingWindowResult(policy Policy, allowed bool, count int, oldest time.Time, now time.Time) Result {
+	result := Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  policy.Limit - count,
+		ResetAfter: oldest.Add(policy.Window).Sub(now),
+	}
+	if !allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// Both scripts take the limit, the window and the current time in microseconds,
+// so a request is checked and counted in a single round trip. Timestamps go back
+// to Redis as the strings they came in as, Lua's tostring would round them.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+  tokens = limit
+  updated = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - updated) * limit / window)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', ARGV[3])
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+return {allowed, tostring(tokens)}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local oldestAt = ARGV[3]
+if oldest[2] then
+  oldestAt = oldest[2]
+end
+return {allowed, count, oldestAt}
+`)
+
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore shares the counters between instances through anything that speaks the Redis protocol.
+// The current time comes from the API instance, so their clocks should be in sync.
+func NewRedisStore(client redis.Scripter, prefix string) Store {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	args := []interface{}{policy.Limit, policy.Window.Microseconds(), now.UnixMicro()}
+
+	if policy.Algorithm == SlidingWindow {
+		// The member only has to be unique, requests in the same microsecond must not collapse
+		member, err := randomMember()
+		if err != nil {
+			return Result{}, err
+		}
+		values, err := slidingWindowScript.Run(ctx, s.client, []string{s.prefix + key}, append(args, member)...).Slice()
+		if err != nil {
+			return Result{}, err
+		}
+		if len(values) != 3 {
+			return Result{}, fmt.Errorf("unexpected sliding window reply %v", values)
+		}
+		count, _ := values[1].(int64)
+		oldest, err := strconv.ParseFloat(fmt.Sprint(values[2]), 64)
+		if err != nil {
+			return Result{}, err
+		}
+		return slidingWindowResult(policy, values[0] == int64(1), int(count), time.UnixMicro(int64(oldest)), now), nil
+	}
+
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key}, args...).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply %v", values)
+	}
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return tokenBucketResult(policy, values[0] == int64(1), tokens), nil
+}
+
+func randomMember() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,42 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header carries the request ID on HTTP requests and responses, and in lower case as gRPC metadata.
+const Header = "X-Request-ID"
+
+const maxLength = 128
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Resolve keeps an ID the caller sent, so a request can be followed across services, and makes one up otherwise.
+func Resolve(incoming string) string {
+	if incoming != "" && len(incoming) <= maxLength && isPrintable(incoming) {
+		return incoming
+	}
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func isPrintable(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x21 || value[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -9,6 +9,7 @@ package userpb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -230,7 +231,16 @@ func (x *CreateUserResponse) GetId() string {
 }
 
 type GetAllUsersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Limit  int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// One of "name", "email" or "create_at"; defaults to "create_at"
+	SortBy        string                 `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortDesc      bool                   `protobuf:"varint,4,opt,name=sort_desc,json=sortDesc,proto3" json:"sort_desc,omitempty"`
+	EmailPrefix   string                 `protobuf:"bytes,5,opt,name=email_prefix,json=emailPrefix,proto3" json:"email_prefix,omitempty"`
+	NameContains  string                 `protobuf:"bytes,6,opt,name=name_contains,json=nameContains,proto3" json:"name_contains,omitempty"`
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -265,9 +275,70 @@ func (*GetAllUsersRequest) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{4}
 }
 
+func (x *GetAllUsersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetAllUsersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetAllUsersRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *GetAllUsersRequest) GetSortDesc() bool {
+	if x != nil {
+		return x.SortDesc
+	}
+	return false
+}
+
+func (x *GetAllUsersRequest) GetEmailPrefix() string {
+	if x != nil {
+		return x.EmailPrefix
+	}
+	return ""
+}
+
+func (x *GetAllUsersRequest) GetNameContains() string {
+	if x != nil {
+		return x.NameContains
+	}
+	return ""
+}
+
+func (x *GetAllUsersRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *GetAllUsersRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
 type GetAllUsersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Users  []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Total  int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Limit  int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Offset of the next page, 0 when this is the last page
+	NextOffset    int32 `protobuf:"varint,5,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -309,6 +380,34 @@ func (x *GetAllUsersResponse) GetUsers() []*User {
 	return nil
 }
 
+func (x *GetAllUsersResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetAllUsersResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetAllUsersResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetAllUsersResponse) GetNextOffset() int32 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
 type UpdateUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -582,7 +681,7 @@ var File_proto_user_proto protoreflect.FileDescriptor
 
 const file_proto_user_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/user.proto\x12\x04user\" \n" +
+	"\x10proto/user.proto\x12\x04user\x1a\x1fgoogle/protobuf/timestamp.proto\" \n" +
 	"\x0eGetUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"K\n" +
 	"\x0fGetUserResponse\x12\x0e\n" +
@@ -594,11 +693,24 @@ const file_proto_user_proto_rawDesc = "" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
 	"\bpassword\x18\x03 \x01(\tR\bpassword\"$\n" +
 	"\x12CreateUserResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
-	"\x12GetAllUsersRequest\"7\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xc4\x02\n" +
+	"\x12GetAllUsersRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x17\n" +
+	"\asort_by\x18\x03 \x01(\tR\x06sortBy\x12\x1b\n" +
+	"\tsort_desc\x18\x04 \x01(\bR\bsortDesc\x12!\n" +
+	"\femail_prefix\x18\x05 \x01(\tR\vemailPrefix\x12#\n" +
+	"\rname_contains\x18\x06 \x01(\tR\fnameContains\x12?\n" +
+	"\rcreated_after\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\"\x9c\x01\n" +
 	"\x13GetAllUsersResponse\x12 \n" +
 	"\x05users\x18\x01 \x03(\v2\n" +
-	".user.UserR\x05users\"M\n" +
+	".user.UserR\x05users\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12\x1f\n" +
+	"\vnext_offset\x18\x05 \x01(\x05R\n" +
+	"nextOffset\"M\n" +
 	"\x11UpdateUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -637,35 +749,38 @@ func file_proto_user_proto_rawDescGZIP() []byte {
 
 var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_user_proto_goTypes = []any{
-	(*GetUserRequest)(nil),      // 0: user.GetUserRequest
-	(*GetUserResponse)(nil),     // 1: user.GetUserResponse
-	(*CreateUserRequest)(nil),   // 2: user.CreateUserRequest
-	(*CreateUserResponse)(nil),  // 3: user.CreateUserResponse
-	(*GetAllUsersRequest)(nil),  // 4: user.GetAllUsersRequest
-	(*GetAllUsersResponse)(nil), // 5: user.GetAllUsersResponse
-	(*UpdateUserRequest)(nil),   // 6: user.UpdateUserRequest
-	(*UpdateUserResponse)(nil),  // 7: user.UpdateUserResponse
-	(*DeleteUserRequest)(nil),   // 8: user.DeleteUserRequest
-	(*DeleteUserResponse)(nil),  // 9: user.DeleteUserResponse
-	(*User)(nil),                // 10: user.User
+	(*GetUserRequest)(nil),        // 0: user.GetUserRequest
+	(*GetUserResponse)(nil),       // 1: user.GetUserResponse
+	(*CreateUserRequest)(nil),     // 2: user.CreateUserRequest
+	(*CreateUserResponse)(nil),    // 3: user.CreateUserResponse
+	(*GetAllUsersRequest)(nil),    // 4: user.GetAllUsersRequest
+	(*GetAllUsersResponse)(nil),   // 5: user.GetAllUsersResponse
+	(*UpdateUserRequest)(nil),     // 6: user.UpdateUserRequest
+	(*UpdateUserResponse)(nil),    // 7: user.UpdateUserResponse
+	(*DeleteUserRequest)(nil),     // 8: user.DeleteUserRequest
+	(*DeleteUserResponse)(nil),    // 9: user.DeleteUserResponse
+	(*User)(nil),                  // 10: user.User
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
 }
 var file_proto_user_proto_depIdxs = []int32{
-	10, // 0: user.GetAllUsersResponse.users:type_name -> user.User
-	0,  // 1: user.UserService.GetUser:input_type -> user.GetUserRequest
-	2,  // 2: user.UserService.CreateUser:input_type -> user.CreateUserRequest
-	4,  // 3: user.UserService.GetAllUsers:input_type -> user.GetAllUsersRequest
-	6,  // 4: user.UserService.UpdateUserByID:input_type -> user.UpdateUserRequest
-	8,  // 5: user.UserService.DeleteUserByID:input_type -> user.DeleteUserRequest
-	1,  // 6: user.UserService.GetUser:output_type -> user.GetUserResponse
-	3,  // 7: user.UserService.CreateUser:output_type -> user.CreateUserResponse
-	5,  // 8: user.UserService.GetAllUsers:output_type -> user.GetAllUsersResponse
-	7,  // 9: user.UserService.UpdateUserByID:output_type -> user.UpdateUserResponse
-	9,  // 10: user.UserService.DeleteUserByID:output_type -> user.DeleteUserResponse
-	6,  // [6:11] is the sub-list for method output_type
-	1,  // [1:6] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	11, // 0: user.GetAllUsersRequest.created_after:type_name -> google.protobuf.Timestamp
+	11, // 1: user.GetAllUsersRequest.created_before:type_name -> google.protobuf.Timestamp
+	10, // 2: user.GetAllUsersResponse.users:type_name -> user.User
+	0,  // 3: user.UserService.GetUser:input_type -> user.GetUserRequest
+	2,  // 4: user.UserService.CreateUser:input_type -> user.CreateUserRequest
+	4,  // 5: user.UserService.GetAllUsers:input_type -> user.GetAllUsersRequest
+	6,  // 6: user.UserService.UpdateUserByID:input_type -> user.UpdateUserRequest
+	8,  // 7: user.UserService.DeleteUserByID:input_type -> user.DeleteUserRequest
+	1,  // 8: user.UserService.GetUser:output_type -> user.GetUserResponse
+	3,  // 9: user.UserService.CreateUser:output_type -> user.CreateUserResponse
+	5,  // 10: user.UserService.GetAllUsers:output_type -> user.GetAllUsersResponse
+	7,  // 11: user.UserService.UpdateUserByID:output_type -> user.UpdateUserResponse
+	9,  // 12: user.UserService.DeleteUserByID:output_type -> user.DeleteUserResponse
+	8,  // [8:13] is the sub-list for method output_type
+	3,  // [3:8] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_proto_user_proto_init() }
@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/middleware"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newPrincipalApp answers with the principal Protected put into the request.
+func newPrincipalApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/me", middleware.Protected(middleware.NewTokenAuthenticator(testKeys, testTokenValidation, nil, nil)), func(ctx *fiber.Ctx) error {
+		principal := middleware.PrincipalFrom(ctx)
+		if domain.PrincipalFrom(ctx.UserContext()) != principal {
+			return fiber.ErrInternalServerError
+		}
+		return ctx.JSON(principal)
+	})
+	return app
+}
+
+func callWithClaims(t *testing.T, app *fiber.App, claims ports.AccessTokenClaims) (int, domain.Principal) {
+	signed, err := testKeys.Sign(claims)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	var principal domain.Principal
+	_ = jsonDecode(resp, &principal)
+	return resp.StatusCode, principal
+}
+
+func TestProtected_PutsTypedPrincipalIntoLocals(t *testing.T) {
+	claims := newTestAccessClaims()
+	claims.Email = "alice@example.com"
+	claims.Roles = []string{domain.RoleUser}
+
+	status, principal := callWithClaims(t, newPrincipalApp(), claims)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, claims.Subject, principal.UserID)
+	assert.Equal(t, "alice@example.com", principal.Email)
+	assert.Equal(t, []string{domain.RoleUser}, principal.Roles)
+	assert.Equal(t, claims.ID, principal.TokenID)
+	assert.Equal(t, claims.ExpiresAt.Unix(), principal.ExpiresAt.Unix())
+}
+
+func TestProtected_ValidatesRegisteredClaims(t *testing.T) {
+	app := newPrincipalApp()
+	now := time.Now()
+
+	cases := map[string]func(c *ports.AccessTokenClaims){
+		"wrong issuer":       func(c *ports.AccessTokenClaims) { c.Issuer = "someone-else" },
+		"no issuer":          func(c *ports.AccessTokenClaims) { c.Issuer = "" },
+		"wrong audience":     func(c *ports.AccessTokenClaims) { c.Audience = jwt.ClaimStrings{"another-api"} },
+		"expired":            func(c *ports.AccessTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) },
+		"no expiry":          func(c *ports.AccessTokenClaims) { c.ExpiresAt = nil },
+		"not valid yet":      func(c *ports.AccessTokenClaims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) },
+		"issued in a minute": func(c *ports.AccessTokenClaims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Minute)) },
+		"no issued at":       func(c *ports.AccessTokenClaims) { c.IssuedAt = nil },
+		"no jti":             func(c *ports.AccessTokenClaims) { c.ID = "" },
+		"no subject":         func(c *ports.AccessTokenClaims) { c.Subject = "" },
+	}
+	for name, change := range cases {
+		claims := newTestAccessClaims()
+		change(&claims)
+		status, _ := callWithClaims(t, app, claims)
+		assert.Equal(t, fiber.StatusUnauthorized, status, name)
+	}
+}
+
+func TestProtected_AllowsClockSkew(t *testing.T) {
+	app := newPrincipalApp()
+	now := time.Now()
+
+	// Within the 30 second leeway a slightly fast or slow clock is fine
+	claims := newTestAccessClaims()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(-10 * time.Second))
+	status, _ := callWithClaims(t, app, claims)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	claims = newTestAccessClaims()
+	claims.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second))
+	claims.IssuedAt = jwt.NewNumericDate(now.Add(10 * time.Second))
+	status, _ = callWithClaims(t, app, claims)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestAuthService_IssuesStandardClaims(t *testing.T) {
+	authService, user := newTestAuthService(t)
+
+	first, err := authService.LoginUser(context.Background(), user.Email, "securepassword")
+	require.NoError(t, err)
+	second, err := authService.LoginUser(context.Background(), user.Email, "securepassword")
+	require.NoError(t, err)
+
+	var claims, other ports.AccessTokenClaims
+	_, err = testKeys.Parse(first.AccessToken, &claims, jwt.WithIssuer(testTokenValidation.Issuer), jwt.WithAudience(testTokenValidation.Audience))
+	require.NoError(t, err)
+	_, err = testKeys.Parse(second.AccessToken, &other)
+	require.NoError(t, err)
+
+	assert.Equal(t, user.ID.Hex(), claims.Subject)
+	assert.Equal(t, user.Email, claims.Email)
+	assert.NotNil(t, claims.IssuedAt)
+	assert.NotNil(t, claims.NotBefore)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotEqual(t, claims.ID, other.ID)
+}
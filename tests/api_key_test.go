@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAPIKeyRepository(t *testing.T, repo ports.APIKeyRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	expiresAt := now.Add(time.Hour)
+
+	first := &domain.APIKey{Name: "batch", Prefix: "prefix-1", KeyHash: "hash-1", Scopes: []domain.Permission{domain.PermissionUsersList, domain.PermissionUsersRead}, CreatedAt: now, ExpiresAt: &expiresAt}
+	second := &domain.APIKey{Name: "report", Prefix: "prefix-2", KeyHash: "hash-2", Scopes: []domain.Permission{domain.PermissionUsersList}, CreatedAt: now.Add(time.Second)}
+	require.NoError(t, repo.CreateAPIKey(ctx, first))
+	require.NoError(t, repo.CreateAPIKey(ctx, second))
+	assert.False(t, first.ID.IsZero())
+
+	stored, err := repo.GetAPIKeyByPrefix(ctx, "prefix-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.ID)
+	assert.Equal(t, "hash-1", stored.KeyHash)
+	assert.Equal(t, first.Scopes, stored.Scopes)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*stored.ExpiresAt))
+
+	_, err = repo.GetAPIKeyByPrefix(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+
+	keys, err := repo.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "batch", keys[0].Name)
+	assert.Equal(t, "report", keys[1].Name)
+
+	// The first revocation time is kept
+	revokedAt := now.Add(time.Minute)
+	require.NoError(t, repo.RevokeAPIKey(ctx, first.ID.Hex(), revokedAt))
+	require.NoError(t, repo.RevokeAPIKey(ctx, first.ID.Hex(), revokedAt.Add(time.Hour)))
+	stored, err = repo.GetAPIKeyByPrefix(ctx, "prefix-1")
+	require.NoError(t, err)
+	require.NotNil(t, stored.RevokedAt)
+	assert.True(t, revokedAt.Equal(*stored.RevokedAt))
+
+	assert.ErrorIs(t, repo.RevokeAPIKey(ctx, "000000000000000000000000", now), domain.ErrAPIKeyNotFound)
+	assert.ErrorIs(t, repo.RevokeAPIKey(ctx, "not-an-id", now), domain.ErrInvalidUserID)
+}
+
+func TestMemoryAPIKeyRepository(t *testing.T) {
+	testAPIKeyRepository(t, memory_repository.NewAPIKeyRepository())
+}
+
+func TestGormAPIKeyRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "api_keys.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	testAPIKeyRepository(t, gorm_repository.NewAPIKeyRepository(db, 5*time.Second))
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	apiKeyService := services.NewAPIKeyService(memory_repository.NewAPIKeyRepository())
+
+	created, err := apiKeyService.CreateAPIKey(ctx, ports.CreateAPIKeyInput{Name: "batch", Scopes: []string{"users:list", "users:list"}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, domain.APIKeyPrefix+created.APIKey.Prefix+"_"))
+	assert.Equal(t, []domain.Permission{domain.PermissionUsersList}, created.APIKey.Scopes)
+
+	principal, err := apiKeyService.AuthenticateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, created.APIKey.ID.Hex(), principal.APIKeyID)
+	assert.Empty(t, principal.UserID)
+	assert.True(t, principal.HasPermission(domain.PermissionUsersList))
+	assert.False(t, principal.HasPermission(domain.PermissionUsersDelete))
+
+	for _, key := range []string{"", "garbage", created.Key + "x", domain.APIKeyPrefix + "unknown_secret"} {
+		_, err = apiKeyService.AuthenticateAPIKey(ctx, key)
+		assert.ErrorIs(t, err, domain.ErrInvalidAPIKey, key)
+	}
+
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	expiring, err := apiKeyService.CreateAPIKey(ctx, ports.CreateAPIKeyInput{Name: "short", Scopes: []string{"users:read"}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	_, err = apiKeyService.AuthenticateAPIKey(ctx, expiring.Key)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = apiKeyService.AuthenticateAPIKey(ctx, expiring.Key)
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_ValidatesInput(t *testing.T) {
+	apiKeyService := services.NewAPIKeyService(memory_repository.NewAPIKeyRepository())
+	past := time.Now().Add(-time.Minute)
+
+	_, err := apiKeyService.CreateAPIKey(context.Background(), ports.CreateAPIKeyInput{Scopes: []string{"users:fly"}, ExpiresAt: &past})
+	var v *domain.ValidationError
+	require.ErrorAs(t, err, &v)
+	fields := map[string]string{}
+	for _, field := range v.Fields {
+		fields[field.Field] = field.Code
+	}
+	assert.Equal(t, map[string]string{
+		"name":       domain.ValidationRequired,
+		"scopes":     domain.ValidationInvalidValue,
+		"expires_at": domain.ValidationInvalidValue,
+	}, fields)
+}
+
+func (a *testApp) doWithHeader(method string, path string, header string, value string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(header, value)
+	resp, err := a.app.Test(req, -1)
+	require.NoError(a.t, err)
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAPIKeys_AdminManagesKeysForServices(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodPost, "/api-keys", bobToken, fiber.Map{"name": "mine", "scopes": []string{"users:list"}})
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, body := app.do(fiber.MethodPost, "/api-keys", adminToken, fiber.Map{"name": "batch", "scopes": []string{"users:wizard"}})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "validation_failed", body["code"])
+
+	status, body = app.do(fiber.MethodPost, "/api-keys", adminToken, fiber.Map{"name": "batch", "scopes": []string{"users:list"}})
+	require.Equal(t, fiber.StatusCreated, status)
+	key := body["key"].(string)
+	apiKey := body["api_key"].(map[string]interface{})
+	assert.NotContains(t, apiKey, "key_hash")
+
+	// Both headers work and the key only reaches what its scopes allow
+	assert.Equal(t, fiber.StatusOK, app.doWithHeader(fiber.MethodGet, "/users", "X-API-Key", key))
+	assert.Equal(t, fiber.StatusOK, app.doWithHeader(fiber.MethodGet, "/users", "Authorization", "ApiKey "+key))
+	assert.Equal(t, fiber.StatusForbidden, app.doWithHeader(fiber.MethodGet, "/users/"+bobID, "X-API-Key", key))
+	assert.Equal(t, fiber.StatusUnauthorized, app.doWithHeader(fiber.MethodGet, "/users", "X-API-Key", key+"x"))
+
+	status, body = app.do(fiber.MethodGet, "/api-keys", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, body["api_keys"], 1)
+
+	status, _ = app.do(fiber.MethodDelete, "/api-keys/"+apiKey["id"].(string), adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, fiber.StatusUnauthorized, app.doWithHeader(fiber.MethodGet, "/users", "X-API-Key", key))
+
+	status, body = app.do(fiber.MethodDelete, "/api-keys/000000000000000000000000", adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "api_key_not_found", body["code"])
+}
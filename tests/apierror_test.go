@@ -0,0 +1,142 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	httpadapter "golang-rest/internal/adapters/inbound/http"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/apierror"
+	"golang-rest/internal/infrastructure/middleware"
+	"golang-rest/internal/infrastructure/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIError_Resolve(t *testing.T) {
+	cases := []struct {
+		err        error
+		httpStatus int
+		grpcCode   codes.Code
+		code       string
+	}{
+		{domain.ErrUserNotFound, http.StatusNotFound, codes.NotFound, "user_not_found"},
+		{fmt.Errorf("%w: bob@example.com", domain.ErrEmailAlreadyExists), http.StatusConflict, codes.AlreadyExists, "email_already_exists"},
+		{domain.ErrInvalidUserID, http.StatusBadRequest, codes.InvalidArgument, "invalid_id"},
+		{domain.ErrInvalidCredentials, http.StatusUnauthorized, codes.Unauthenticated, "invalid_credentials"},
+		{domain.ErrNotOwner, http.StatusForbidden, codes.PermissionDenied, "not_owner"},
+		{fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidQuery, "age"), http.StatusBadRequest, codes.InvalidArgument, "invalid_query"},
+		{&domain.RetryError{Err: domain.ErrLoginThrottled, RetryAfter: time.Minute}, http.StatusTooManyRequests, codes.ResourceExhausted, "too_many_attempts"},
+		{domain.ErrTokenGeneration, http.StatusInternalServerError, codes.Internal, "token_generation_failed"},
+		{fmt.Errorf("find: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, codes.DeadlineExceeded, "timeout"},
+		{errors.New("server selection error: connection refused"), http.StatusInternalServerError, codes.Internal, "internal_error"},
+	}
+	for _, c := range cases {
+		problem := apierror.Resolve(c.err, "Failed")
+		assert.Equal(t, c.httpStatus, problem.HTTPStatus, c.err.Error())
+		assert.Equal(t, c.grpcCode, problem.GRPCCode, c.err.Error())
+		assert.Equal(t, c.code, problem.Code, c.err.Error())
+	}
+
+	// Infrastructure errors never reach the client
+	assert.Equal(t, "Failed", apierror.Resolve(errors.New("mongo: connection refused"), "Failed").Message)
+	// Input errors keep their detail
+	assert.Contains(t, apierror.Resolve(fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidQuery, "age"), "").Message, `"age"`)
+
+	v := &domain.ValidationError{}
+	v.Add("email", domain.ValidationInvalidEmail, "must be a valid email address")
+	problem := apierror.Resolve(v, "")
+	assert.Equal(t, http.StatusBadRequest, problem.HTTPStatus)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Len(t, problem.Fields, 1)
+}
+
+func TestAPIError_GRPCStatus(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	st, ok := status.FromError(apierror.GRPCStatus(ctx, domain.ErrUserNotFound))
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "user_not_found", info.Reason)
+	assert.Equal(t, "req-1", info.Metadata["request_id"])
+
+	v := &domain.ValidationError{}
+	v.Add("name", domain.ValidationRequired, "is required")
+	st, _ = status.FromError(apierror.GRPCStatus(ctx, v))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 2)
+	assert.Equal(t, "name", st.Details()[1].(*errdetails.BadRequest).FieldViolations[0].Field)
+}
+
+// unavailableUserRepository fails every lookup the way a database outage would.
+type unavailableUserRepository struct {
+	ports.UserRepositoryInterface
+}
+
+func (unavailableUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return nil, errors.New("server selection error: context deadline exceeded, current topology: Unknown")
+}
+
+func TestErrorEnvelope_GetUserByID(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Bob", "bob@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, body := app.do(fiber.MethodGet, "/users/not-an-id", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status, "a malformed id is still not the caller's own")
+	assert.Equal(t, "not_owner", body["code"])
+	assert.NotEmpty(t, body["request_id"])
+
+	_, adminToken := newAdminToken(t)
+	status, body = app.do(fiber.MethodGet, "/users/not-an-id", adminToken, nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "invalid_id", body["code"])
+	status, body = app.do(fiber.MethodGet, "/users/"+primitive.NewObjectID().Hex(), adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "user_not_found", body["code"])
+	status, _ = app.do(fiber.MethodGet, "/users/"+bobID, adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// An outage is a 500 and does not leak the driver message
+	userRepository := unavailableUserRepository{memory_repository.NewUserRepository()}
+	userService := services.NewUserService(userRepository, nil, nil, services.UserConfig{})
+	outage := fiber.New()
+	outage.Use(middleware.RequestContext(context.Background()))
+	outage.Use(middleware.RequestID())
+	httpadapter.Setup(outage, userService, nil, nil, nil, nil, nil, nil, nil, nil, httpadapter.AccessTokens{Keys: testKeys, Validation: testTokenValidation}, httpadapter.RateLimits{})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/"+bobID, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set(requestid.Header, "trace-42")
+	resp, err := outage.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "trace-42", resp.Header.Get(requestid.Header))
+
+	var envelope apierror.Envelope
+	require.NoError(t, jsonDecode(resp, &envelope))
+	assert.Equal(t, apierror.Envelope{Error: "Failed to get user", Code: "internal_error", RequestID: "trace-42"}, envelope)
+}
+
+// newAdminToken signs an access token for a made-up admin with the test keys.
+func newAdminToken(t *testing.T) (string, string) {
+	claims := newTestAccessClaims()
+	claims.Roles = []string{domain.RoleAdmin}
+	signed, err := testKeys.Sign(claims)
+	require.NoError(t, err)
+	return claims.Subject, signed
+}
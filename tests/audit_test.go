@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/file_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func (a *testApp) auditEvents(token string, query string) []map[string]interface{} {
+	status, body := a.do(fiber.MethodGet, "/audit?"+query, token, nil)
+	require.Equal(a.t, fiber.StatusOK, status)
+
+	var events []map[string]interface{}
+	for _, event := range body["events"].([]interface{}) {
+		events = append(events, event.(map[string]interface{}))
+	}
+	return events
+}
+
+func TestAudit_RecordsUserMutationsAndLogins(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	adminID, adminToken := app.login("root@example.com")
+	status, _ := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "bob@example.com", "password": "Wrong123!"})
+	require.Equal(t, fiber.StatusUnauthorized, status)
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, _ = app.updateUser(bobID, bobToken, fiber.Map{"name": "Robert"})
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+bobID, adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	events := app.auditEvents(adminToken, "target_id="+bobID)
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, event["action"].(string))
+	}
+	assert.Equal(t, []string{domain.AuditUserDelete, domain.AuditUserUpdate, domain.AuditLogin, domain.AuditUserRegister}, actions)
+
+	deleted, updated, login, register := events[0], events[1], events[2], events[3]
+	assert.Equal(t, adminID, deleted["actor_id"])
+	assert.Equal(t, domain.AuditActorUser, deleted["actor_type"])
+	assert.Equal(t, bobID, updated["actor_id"])
+	assert.Equal(t, map[string]interface{}{"name": map[string]interface{}{"from": "Bob", "to": "Robert"}}, updated["changes"])
+	assert.Equal(t, domain.AuditOutcomeSuccess, login["outcome"])
+	assert.Equal(t, "bob@example.com", login["target_email"])
+	assert.Nil(t, register["actor_id"])
+	for _, event := range events {
+		assert.NotEmpty(t, event["ip"])
+		assert.NotEmpty(t, event["request_id"])
+	}
+
+	// The failed login only knew the email
+	failed := app.auditEvents(adminToken, "target_email=bob@example.com&outcome=failure")
+	require.Len(t, failed, 1)
+	assert.Equal(t, domain.AuditLogin, failed[0]["action"])
+	assert.Equal(t, "invalid_credentials", failed[0]["reason"])
+	assert.Nil(t, failed[0]["target_id"])
+}
+
+func TestAudit_RecordsMFAChallenges(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+	app.enrollMFA(bobID, bobToken)
+
+	status, _ := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "bob@example.com", "password": "Secret123!"})
+	require.Equal(t, fiber.StatusOK, status)
+
+	events := app.auditEvents(adminToken, "action="+domain.AuditLogin+"&limit=1")
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.AuditOutcomeMFA, events[0]["outcome"])
+
+	mfa := app.auditEvents(adminToken, "target_id="+bobID+"&action="+domain.AuditMFAConfirm)
+	require.Len(t, mfa, 1)
+	assert.Equal(t, domain.AuditOutcomeSuccess, mfa[0]["outcome"])
+}
+
+func TestAudit_OnlyAdminsRead(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	_, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodGet, "/audit", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodGet, "/audit?from=yesterday", adminToken, nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, body := app.do(fiber.MethodGet, "/audit?limit=1", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, body["events"], 1)
+	assert.Equal(t, float64(4), body["total"])
+	assert.NotNil(t, body["next"])
+}
+
+func testAuditRepository(t *testing.T, repo ports.AuditRepositoryInterface) {
+	ctx := context.Background()
+	start := time.Now().Truncate(time.Second)
+
+	page, err := repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	assert.Empty(t, page.Events)
+
+	for i, event := range []domain.AuditEvent{
+		{Action: domain.AuditUserRegister, Outcome: domain.AuditOutcomeSuccess, TargetID: "user-1"},
+		{Action: domain.AuditLogin, Outcome: domain.AuditOutcomeFailure, Reason: "invalid_credentials", TargetEmail: "bob@example.com"},
+		{Action: domain.AuditUserUpdate, Outcome: domain.AuditOutcomeSuccess, ActorID: "user-1", TargetID: "user-1",
+			Changes: map[string]domain.AuditChange{"name": {From: "Bob", To: "Robert"}}},
+	} {
+		event.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.RecordAuditEvent(ctx, &event))
+		assert.False(t, event.ID.IsZero())
+	}
+
+	page, err = repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 3)
+	assert.Equal(t, domain.AuditUserUpdate, page.Events[0].Action)
+	assert.Equal(t, domain.AuditChange{From: "Bob", To: "Robert"}, page.Events[0].Changes["name"])
+
+	page, err = repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10, TargetID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	page, err = repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10, Outcome: domain.AuditOutcomeFailure})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, "bob@example.com", page.Events[0].TargetEmail)
+	from, to := start.Add(time.Second), start.Add(2*time.Second)
+	page, err = repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10, From: &from, To: &to})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, domain.AuditLogin, page.Events[0].Action)
+
+	page, err = repo.ListAuditEvents(ctx, domain.AuditQuery{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, domain.AuditLogin, page.Events[0].Action)
+	assert.True(t, page.HasNext())
+}
+
+func TestMemoryAuditRepository(t *testing.T) {
+	testAuditRepository(t, memory_repository.NewAuditRepository())
+}
+
+func TestFileAuditRepository(t *testing.T) {
+	testAuditRepository(t, file_repository.NewAuditRepository(filepath.Join(t.TempDir(), "audit.log")))
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"time"
+)
+
+var testAuthConfig = services.AuthConfig{
+	Issuer:          testTokenValidation.Issuer,
+	Audience:        []string{testTokenValidation.Audience},
+	AccessTokenTTL:  15 * time.Minute,
+	RefreshTokenTTL: time.Hour,
+}
+
+func newTestAuthService(t *testing.T) (ports.AuthService, *domain.User) {
+	userRepository := memory_repository.NewUserRepository()
+	user := &domain.User{Name: "Test User", Email: "testuser@example.com", Password: "securepassword"}
+	require.NoError(t, userRepository.CreateUser(context.Background(), user))
+
+	return services.NewAuthService(userRepository, memory_repository.NewRefreshTokenRepository(), nil, nil, nil, nil, testKeys, testAuthConfig), user
+}
+
+func TestAuthService_LoginUser(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("securepassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	testUser := domain.User{
+		ID:       primitive.NewObjectID(),
+		Name:     "Test User",
+		Email:    "testuser@example.com",
+		Password: string(hashedPassword),
+	}
+	mockRepo := &MockUserRepository{Users: map[string]domain.User{testUser.Email: testUser}}
+	mockRepo.On("GetUserLoginByEmail", testUser.Email).Return(&testUser, nil)
+	authService := services.NewAuthService(mockRepo, memory_repository.NewRefreshTokenRepository(), nil, nil, nil, nil, testKeys, testAuthConfig)
+
+	_, err = authService.LoginUser(ctx, testUser.Email, "wrongpassword")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	tokens, err := authService.LoginUser(ctx, testUser.Email, "securepassword")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), tokens.AccessTokenExpiresAt, time.Minute)
+
+	token, err := testKeys.Parse(tokens.AccessToken, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, testUser.ID.Hex(), token.Claims.(jwt.MapClaims)["sub"])
+}
+
+func TestAuthService_RefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	authService, user := newTestAuthService(t)
+
+	first, err := authService.LoginUser(ctx, user.Email, "securepassword")
+	require.NoError(t, err)
+
+	second, err := authService.RefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	third, err := authService.RefreshToken(ctx, second.RefreshToken)
+	require.NoError(t, err)
+
+	// Replaying a rotated token revokes the whole family, including the newest member
+	_, err = authService.RefreshToken(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrTokenReused)
+	_, err = authService.RefreshToken(ctx, third.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	// A separate login is a separate family and is unaffected
+	other, err := authService.LoginUser(ctx, user.Email, "securepassword")
+	require.NoError(t, err)
+	_, err = authService.RefreshToken(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = authService.RefreshToken(ctx, "not-a-token")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctx := context.Background()
+	authService, user := newTestAuthService(t)
+
+	first, err := authService.LoginUser(ctx, user.Email, "securepassword")
+	require.NoError(t, err)
+	second, err := authService.RefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	assert.NoError(t, authService.Logout(ctx, second.RefreshToken))
+	_, err = authService.RefreshToken(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	assert.ErrorIs(t, authService.Logout(ctx, "not-a-token"), domain.ErrInvalidToken)
+}
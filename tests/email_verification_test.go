@@ -0,0 +1,194 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var verificationTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+func verificationToken(t *testing.T, notification domain.Notification) string {
+	match := verificationTokenPattern.FindStringSubmatch(notification.Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+type verificationFixture struct {
+	users        ports.UserRepositoryInterface
+	userService  ports.UserService
+	verification ports.EmailVerificationService
+	notifier     *recordingNotifier
+}
+
+func newVerificationFixture(ttl time.Duration) *verificationFixture {
+	userRepository := memory_repository.NewUserRepository()
+	userNotifier := &recordingNotifier{}
+	verification := services.NewEmailVerificationService(userRepository, userNotifier, services.EmailVerificationConfig{
+		Secret:    []byte("verification-secret"),
+		TokenTTL:  ttl,
+		VerifyURL: "https://example.com/verify-email",
+	})
+	return &verificationFixture{
+		users:        userRepository,
+		userService:  services.NewUserService(userRepository, verification, nil, services.UserConfig{}),
+		verification: verification,
+		notifier:     userNotifier,
+	}
+}
+
+func TestEmailVerification_RegisterAndVerify(t *testing.T) {
+	ctx := context.Background()
+	fixture := newVerificationFixture(time.Hour)
+
+	user, err := fixture.userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "pw"})
+	require.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+
+	notification := fixture.notifier.last()
+	assert.Equal(t, "bob@example.com", notification.To)
+	assert.Contains(t, notification.Body, "https://example.com/verify-email?token=")
+	token := verificationToken(t, notification)
+
+	assert.ErrorIs(t, fixture.verification.VerifyEmail(ctx, token+"x"), domain.ErrInvalidToken)
+	assert.ErrorIs(t, fixture.verification.VerifyEmail(ctx, "garbage"), domain.ErrInvalidToken)
+	require.NoError(t, fixture.verification.VerifyEmail(ctx, token))
+
+	stored, err := fixture.users.GetUserByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, stored.EmailVerified)
+
+	// Already verified accounts get no further emails
+	sent := len(fixture.notifier.notifications)
+	require.NoError(t, fixture.verification.ResendVerificationEmail(ctx, user.ID.Hex()))
+	assert.Len(t, fixture.notifier.notifications, sent)
+}
+
+func TestEmailVerification_EmailChangeNeedsNewVerification(t *testing.T) {
+	ctx := context.Background()
+	fixture := newVerificationFixture(time.Hour)
+
+	user, err := fixture.userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "pw"})
+	require.NoError(t, err)
+	oldToken := verificationToken(t, fixture.notifier.last())
+	require.NoError(t, fixture.verification.VerifyEmail(ctx, oldToken))
+
+	email := "robert@example.com"
+	updated, err := fixture.userService.UpdateUserByID(ctx, user.ID.Hex(), ports.UpdateUserInput{Email: &email})
+	require.NoError(t, err)
+	assert.False(t, updated.EmailVerified)
+	assert.Equal(t, email, fixture.notifier.last().To)
+
+	// The token for the previous address no longer applies
+	assert.ErrorIs(t, fixture.verification.VerifyEmail(ctx, oldToken), domain.ErrInvalidToken)
+	require.NoError(t, fixture.verification.VerifyEmail(ctx, verificationToken(t, fixture.notifier.last())))
+}
+
+func TestEmailVerification_TokenExpires(t *testing.T) {
+	ctx := context.Background()
+	fixture := newVerificationFixture(-time.Minute)
+
+	_, err := fixture.userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "pw"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, fixture.verification.VerifyEmail(ctx, verificationToken(t, fixture.notifier.last())), domain.ErrInvalidToken)
+}
+
+func TestAuthService_RequireVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	fixture := newVerificationFixture(time.Hour)
+	authService := services.NewAuthService(fixture.users, memory_repository.NewRefreshTokenRepository(), nil, nil, nil, nil, testKeys, services.AuthConfig{
+		AccessTokenTTL:       time.Minute,
+		RefreshTokenTTL:      time.Hour,
+		RequireVerifiedEmail: true,
+	})
+
+	_, err := fixture.userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "pw"})
+	require.NoError(t, err)
+
+	_, err = authService.LoginUser(ctx, "bob@example.com", "wrong")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, err = authService.LoginUser(ctx, "bob@example.com", "pw")
+	assert.ErrorIs(t, err, domain.ErrEmailNotVerified)
+
+	require.NoError(t, fixture.verification.VerifyEmail(ctx, verificationToken(t, fixture.notifier.last())))
+	_, err = authService.LoginUser(ctx, "bob@example.com", "pw")
+	assert.NoError(t, err)
+}
+
+func testMarkUserEmailVerified(t *testing.T, repo ports.UserRepositoryInterface) {
+	ctx := context.Background()
+
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pw"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	assert.ErrorIs(t, repo.MarkUserEmailVerified(ctx, user.ID.Hex(), "other@example.com"), domain.ErrUserNotFound)
+	require.NoError(t, repo.MarkUserEmailVerified(ctx, user.ID.Hex(), "bob@example.com"))
+
+	stored, err := repo.GetUserByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, stored.EmailVerified)
+
+	updated, err := repo.UpdateUserByID(ctx, user.ID.Hex(), stored.Version, domain.UserUpdate{Email: ptr("robert@example.com"), EmailVerified: ptr(false)})
+	require.NoError(t, err)
+	assert.False(t, updated.EmailVerified)
+}
+
+func TestGormUserRepository_MarkUserEmailVerified(t *testing.T) {
+	testMarkUserEmailVerified(t, newGormUserRepository(t))
+}
+
+func TestVerifyEmailRequestRoute(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Bob", "bob@example.com")
+	sent := len(app.notifier.notifications)
+
+	status, body := app.do(fiber.MethodPost, "/verify-email/request", "", fiber.Map{"email": "bob@example.com"})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	require.Len(t, app.notifier.notifications, sent+1)
+	assert.Equal(t, "bob@example.com", app.notifier.last().To)
+	token := verificationToken(t, app.notifier.last())
+
+	// Unknown accounts get the same answer and nothing is sent
+	status, unknown := app.do(fiber.MethodPost, "/verify-email/request", "", fiber.Map{"email": "nobody@example.com"})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	assert.Equal(t, body["message"], unknown["message"])
+	assert.Len(t, app.notifier.notifications, sent+1)
+
+	status, _ = app.do(fiber.MethodPost, "/verify-email/request", "", fiber.Map{"email": "not-an-email"})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = app.do(fiber.MethodGet, "/verify-email?token="+url.QueryEscape(token), "", nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodPost, "/verify-email/request", "", fiber.Map{"email": "bob@example.com"})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	assert.Len(t, app.notifier.notifications, sent+1)
+}
+
+func TestVerifyEmailRoute(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Bob", "bob@example.com")
+	token := verificationToken(t, app.notifier.last())
+
+	status, _ := app.do(fiber.MethodGet, "/verify-email", "", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	status, _ = app.do(fiber.MethodGet, "/verify-email?token=nope", "", nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = app.do(fiber.MethodGet, "/verify-email?token="+url.QueryEscape(token), "", nil)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	bobID, bobToken := app.login("bob@example.com")
+	status, body := app.do(fiber.MethodGet, "/users/"+bobID, bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, body["email_verified"])
+}
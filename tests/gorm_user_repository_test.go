@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newGormUserRepository(t *testing.T) ports.UserRepositoryInterface {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "users.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	return gorm_repository.NewUserRepository(db, 5*time.Second)
+}
+
+func TestGormUserRepository_CreateAndFetchUser(t *testing.T) {
+	ctx := context.Background()
+	repo := newGormUserRepository(t)
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "secret"}
+	assert.NoError(t, repo.CreateUser(ctx, user))
+
+	res, err := repo.GetUserByID(ctx, user.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", res.Name)
+	assert.Empty(t, res.Password)
+
+	login, err := repo.GetUserLoginByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, login.Password)
+
+	page, err := repo.GetAllUsers(ctx, domain.UserQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Empty(t, page.Users[0].Password)
+}
+
+func TestGormUserRepository_UniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := newGormUserRepository(t)
+
+	assert.NoError(t, repo.CreateUser(ctx, &domain.User{Name: "Alice", Email: "alice@example.com", Password: "a"}))
+	err := repo.CreateUser(ctx, &domain.User{Name: "Alice 2", Email: "alice@example.com", Password: "b"})
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+}
+
+func TestGormUserRepository_MigrationsAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	repo := newGormUserRepository(t)
+
+	assert.NoError(t, repo.EnsureIndexes(ctx))
+	assert.NoError(t, repo.EnsureIndexes(ctx))
+}
+
+func TestGormUserRepository_UpdateAndDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	repo := newGormUserRepository(t)
+
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123"}
+	assert.NoError(t, repo.CreateUser(ctx, user))
+
+	updated, err := repo.UpdateUserByID(ctx, user.ID.Hex(), user.Version, domain.UserUpdate{Name: ptr("Robert")})
+	assert.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+
+	assert.NoError(t, repo.DeleteUserByID(ctx, user.ID.Hex()))
+	_, err = repo.GetUserByID(ctx, user.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestGormUserRepository_GetAllUsersQuery(t *testing.T) {
+	testUserQueries(t, newGormUserRepository(t))
+}
+
+func TestGormUserRepository_CanceledContext(t *testing.T) {
+	repo := newGormUserRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetAllUsers(ctx, domain.UserQuery{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGormUserRepository_Roles(t *testing.T) {
+	ctx := context.Background()
+	repo := newGormUserRepository(t)
+
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pw", Roles: []string{domain.RoleUser}}
+	assert.NoError(t, repo.CreateUser(ctx, user))
+
+	updated, err := repo.AddUserRole(ctx, user.ID.Hex(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{domain.RoleUser, domain.RoleAdmin}, updated.Roles)
+	_, err = repo.AddUserRole(ctx, user.ID.Hex(), domain.RoleAdmin)
+	assert.NoError(t, err)
+
+	login, err := repo.GetUserLoginByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, login.Roles, 2)
+
+	updated, err = repo.RemoveUserRole(ctx, user.ID.Hex(), domain.RoleUser)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleAdmin}, updated.Roles)
+
+	_, err = repo.AddUserRole(ctx, "000000000000000000000000", domain.RoleAdmin)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestGormUserRepository_InvalidID(t *testing.T) {
+	repo := newGormUserRepository(t)
+
+	_, err := repo.GetUserByID(context.Background(), "not-an-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcadapter "golang-rest/internal/adapters/inbound/grpc"
+	"golang-rest/internal/infrastructure/middleware"
+	"golang-rest/proto/userpb"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+// grpcClient serves the app's services over an in-memory connection, wired like cmd/main.go.
+func (a *testApp) grpcClient() userpb.UserServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := googlegrpc.NewServer(googlegrpc.ChainUnaryInterceptor(
+		grpcadapter.RequestIDInterceptor(),
+		grpcadapter.AuthInterceptor(middleware.NewTokenAuthenticator(testKeys, testTokenValidation, a.revocations, a.sessionRepository), a.apiKeyService, grpcadapter.PublicMethods),
+	))
+	grpcadapter.Setup(server, a.userService)
+	go server.Serve(listener)
+	a.t.Cleanup(server.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}
+	conn, err := googlegrpc.NewClient("passthrough:///bufnet", googlegrpc.WithContextDialer(dialer), googlegrpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(a.t, err)
+	a.t.Cleanup(func() { _ = conn.Close() })
+	return userpb.NewUserServiceClient(conn)
+}
+
+func withMetadata(key string, value string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), key, value)
+}
+
+func grpcCode(err error) codes.Code {
+	return status.Code(err)
+}
+
+func TestGRPC_RequiresCredentialsAndPermissions(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	client := app.grpcClient()
+
+	// Registering stays public, like POST /register
+	_, err := client.CreateUser(context.Background(), &userpb.CreateUserRequest{Name: "Root", Email: "root@example.com", Password: "Secret123!"})
+	require.NoError(t, err)
+	app.register("Bob", "bob@example.com")
+	adminID, adminToken := app.login("root@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+	anonymous := context.Background()
+	asBob := withMetadata("authorization", "Bearer "+bobToken)
+	asAdmin := withMetadata("authorization", "Bearer "+adminToken)
+
+	_, err = client.GetAllUsers(anonymous, &userpb.GetAllUsersRequest{})
+	assert.Equal(t, codes.Unauthenticated, grpcCode(err))
+	_, err = client.DeleteUserByID(anonymous, &userpb.DeleteUserRequest{Id: bobID})
+	assert.Equal(t, codes.Unauthenticated, grpcCode(err))
+	_, err = client.GetAllUsers(withMetadata("authorization", "Bearer "+bobToken+"x"), &userpb.GetAllUsersRequest{})
+	assert.Equal(t, codes.Unauthenticated, grpcCode(err))
+
+	_, err = client.GetAllUsers(asBob, &userpb.GetAllUsersRequest{})
+	assert.Equal(t, codes.PermissionDenied, grpcCode(err))
+
+	// Bob reaches his own record only
+	user, err := client.GetUser(asBob, &userpb.GetUserRequest{Id: bobID})
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", user.GetEmail())
+	_, err = client.UpdateUserByID(asBob, &userpb.UpdateUserRequest{Id: bobID, Name: "Bobby"})
+	assert.NoError(t, err)
+	_, err = client.GetUser(asBob, &userpb.GetUserRequest{Id: adminID})
+	assert.Equal(t, codes.PermissionDenied, grpcCode(err))
+	_, err = client.UpdateUserByID(asBob, &userpb.UpdateUserRequest{Id: adminID, Name: "Mallory"})
+	assert.Equal(t, codes.PermissionDenied, grpcCode(err))
+	_, err = client.DeleteUserByID(asBob, &userpb.DeleteUserRequest{Id: adminID})
+	assert.Equal(t, codes.PermissionDenied, grpcCode(err))
+
+	page, err := client.GetAllUsers(asAdmin, &userpb.GetAllUsersRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.GetTotal())
+	updated, err := client.UpdateUserByID(asAdmin, &userpb.UpdateUserRequest{Id: bobID, Name: "Robert"})
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", updated.GetName())
+
+	// API keys are limited to their scopes here as well
+	status, body := app.do(fiber.MethodPost, "/api-keys", adminToken, fiber.Map{"name": "batch", "scopes": []string{"users:list"}})
+	require.Equal(t, fiber.StatusCreated, status)
+	key := body["key"].(string)
+	_, err = client.GetAllUsers(withMetadata("x-api-key", key), &userpb.GetAllUsersRequest{})
+	assert.NoError(t, err)
+	_, err = client.GetAllUsers(withMetadata("authorization", "ApiKey "+key), &userpb.GetAllUsersRequest{})
+	assert.NoError(t, err)
+	_, err = client.GetUser(withMetadata("x-api-key", key), &userpb.GetUserRequest{Id: bobID})
+	assert.Equal(t, codes.PermissionDenied, grpcCode(err))
+}
@@ -0,0 +1,209 @@
+package repository_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/jwtkeys"
+	"golang-rest/internal/infrastructure/middleware"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testKeys signs every access token in the tests; Ed25519 keys are cheap to make.
+var testKeys = mustKeyManager(jwtkeys.EdDSA)
+
+var testTokenValidation = middleware.TokenValidation{Issuer: "golang-rest-test", Audience: "golang-rest-test-api", Leeway: 30 * time.Second}
+
+// newTestAccessClaims describes a valid token for a made-up user with no roles.
+func newTestAccessClaims() ports.AccessTokenClaims {
+	now := time.Now()
+	return ports.AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testTokenValidation.Issuer,
+			Subject:   primitive.NewObjectID().Hex(),
+			Audience:  jwt.ClaimStrings{testTokenValidation.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        primitive.NewObjectID().Hex(),
+		},
+		Roles: []string{},
+	}
+}
+
+func mustKeyManager(algorithm string) *jwtkeys.KeyManager {
+	key, err := jwtkeys.GenerateKey(algorithm)
+	if err != nil {
+		panic(err)
+	}
+	manager, err := jwtkeys.NewKeyManager(key)
+	if err != nil {
+		panic(err)
+	}
+	return manager
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": "u1", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeyManager_SignAndParse(t *testing.T) {
+	for _, algorithm := range []string{jwtkeys.RS256, jwtkeys.EdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			keys := mustKeyManager(algorithm)
+			signed, err := keys.Sign(testClaims())
+			require.NoError(t, err)
+
+			claims := jwt.MapClaims{}
+			token, err := keys.Parse(signed, claims)
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, token.Method.Alg())
+			assert.Equal(t, keys.JWKS().Keys[0].KeyID, token.Header["kid"])
+			assert.Equal(t, "u1", claims["user_id"])
+		})
+	}
+}
+
+func TestKeyManager_RejectsForeignTokens(t *testing.T) {
+	keys := mustKeyManager(jwtkeys.EdDSA)
+	kid := keys.JWKS().Keys[0].KeyID
+
+	// An HMAC token must not get through, whatever secret it was made with
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	hmac.Header["kid"] = kid
+	signed, err := hmac.SignedString([]byte("guess"))
+	require.NoError(t, err)
+	_, err = keys.Parse(signed, jwt.MapClaims{})
+	assert.Error(t, err)
+
+	// Nor a token from another key that claims our kid
+	other, err := jwtkeys.GenerateKey(jwtkeys.EdDSA)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims())
+	forged.Header["kid"] = kid
+	signed, err = forged.SignedString(other.Private)
+	require.NoError(t, err)
+	_, err = keys.Parse(signed, jwt.MapClaims{})
+	assert.Error(t, err)
+
+	// Nor one without a kid
+	signed, err = jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims()).SignedString(other.Private)
+	require.NoError(t, err)
+	_, err = keys.Parse(signed, jwt.MapClaims{})
+	assert.Error(t, err)
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	keys := mustKeyManager(jwtkeys.EdDSA)
+	oldKID := keys.JWKS().Keys[0].KeyID
+	oldToken, err := keys.Sign(testClaims())
+	require.NoError(t, err)
+
+	next, err := jwtkeys.GenerateKey(jwtkeys.EdDSA)
+	require.NoError(t, err)
+	require.NoError(t, keys.Rotate(next))
+
+	// Tokens from before the rotation keep working, new ones use the new key
+	_, err = keys.Parse(oldToken, jwt.MapClaims{})
+	assert.NoError(t, err)
+	newToken, err := keys.Sign(testClaims())
+	require.NoError(t, err)
+	token, err := keys.Parse(newToken, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, token.Header["kid"])
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, next.ID, jwks.Keys[0].KeyID)
+
+	assert.Error(t, keys.Retire(next.ID))
+	require.NoError(t, keys.Retire(oldKID))
+	_, err = keys.Parse(oldToken, jwt.MapClaims{})
+	assert.Error(t, err)
+	assert.Len(t, keys.JWKS().Keys, 1)
+}
+
+func TestKeyManager_PEM(t *testing.T) {
+	key, err := jwtkeys.GenerateKey(jwtkeys.EdDSA)
+	require.NoError(t, err)
+
+	data, err := key.MarshalPEM()
+	require.NoError(t, err)
+	parsed, err := jwtkeys.ParsePEM(data)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, parsed.ID)
+	assert.NotNil(t, parsed.Private)
+
+	// A public key can only verify, so it cannot be the signing key
+	public := jwtkeys.Key{ID: key.ID, Algorithm: key.Algorithm, Public: key.Public}
+	data, err = public.MarshalPEM()
+	require.NoError(t, err)
+	parsed, err = jwtkeys.ParsePEM(data)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, parsed.ID)
+	assert.Nil(t, parsed.Private)
+	_, err = jwtkeys.NewKeyManager(parsed)
+	assert.Error(t, err)
+
+	// The signer still verifies tokens made with a key it only has the public half of
+	signer, err := jwtkeys.NewKeyManager(key)
+	require.NoError(t, err)
+	signed, err := signer.Sign(testClaims())
+	require.NoError(t, err)
+	other, err := jwtkeys.GenerateKey(jwtkeys.EdDSA)
+	require.NoError(t, err)
+	verifier, err := jwtkeys.NewKeyManager(other, parsed)
+	require.NoError(t, err)
+	_, err = verifier.Parse(signed, jwt.MapClaims{})
+	assert.NoError(t, err)
+
+	_, err = jwtkeys.ParsePEM([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestKeyManager_ThumbprintMatchesRFC7638(t *testing.T) {
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+	key := jwtkeys.Key{Algorithm: jwtkeys.RS256, Public: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}}
+	data, err := key.MarshalPEM()
+	require.NoError(t, err)
+
+	parsed, err := jwtkeys.ParsePEM(data)
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", parsed.ID)
+}
+
+func TestJWKSRoute_VerifiesIssuedTokens(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	_, token := app.login("alice@example.com")
+
+	resp, err := app.app.Test(httptest.NewRequest(fiber.MethodGet, "/.well-known/jwks.json", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var jwks jwtkeys.JWKS
+	require.NoError(t, jsonDecode(resp, &jwks))
+	require.Len(t, jwks.Keys, 1)
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "OKP", jwk.KeyType)
+	assert.Equal(t, "sig", jwk.Use)
+
+	// Another service only needs the published key to check our tokens
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	require.NoError(t, err)
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwk.KeyID, token.Header["kid"])
+		return ed25519.PublicKey(x), nil
+	}, jwt.WithValidMethods([]string{jwk.Algorithm}))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+}
@@ -0,0 +1,210 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLockoutPolicy locks after three failures and has no delay in between, so tests stay fast.
+var testLockoutPolicy = domain.LockoutPolicy{
+	MaxAccountFailures: 3,
+	MaxIPFailures:      10,
+	LockoutDuration:    time.Minute,
+	FailureWindow:      time.Minute,
+}
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	policy := domain.LockoutPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Duration(0), policy.Delay(0))
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 4*time.Second, policy.Delay(3))
+	assert.Equal(t, 5*time.Second, policy.Delay(4))
+	assert.Equal(t, 5*time.Second, policy.Delay(100))
+}
+
+func testLoginAttemptRepository(t *testing.T, repo ports.LoginAttemptRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now()
+
+	attempts, err := repo.GetLoginAttempts(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts.Failures)
+
+	for i := 1; i <= 3; i++ {
+		attempts, err = repo.RecordLoginFailure(ctx, "account:a", now, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, attempts.Failures)
+	}
+	attempts, err = repo.GetLoginAttempts(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts.Failures)
+	assert.False(t, attempts.IsLocked(now))
+
+	// A failure after a quiet window starts counting again
+	attempts, err = repo.RecordLoginFailure(ctx, "account:a", now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+
+	require.NoError(t, repo.LockLogin(ctx, "account:a", now.Add(time.Hour)))
+	attempts, err = repo.GetLoginAttempts(ctx, "account:a")
+	require.NoError(t, err)
+	assert.True(t, attempts.IsLocked(now))
+
+	// Counting starts over after the window, the lock stays
+	attempts, err = repo.RecordLoginFailure(ctx, "account:a", now.Add(4*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+	assert.True(t, attempts.IsLocked(now))
+
+	require.NoError(t, repo.ResetLoginAttempts(ctx, "account:a"))
+	attempts, err = repo.GetLoginAttempts(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts.Failures)
+	assert.False(t, attempts.IsLocked(now))
+
+	// Other keys are counted separately
+	attempts, err = repo.RecordLoginFailure(ctx, "ip:10.0.0.1", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+
+	// Concurrent failures are all counted
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.RecordLoginFailure(ctx, "account:b", now, time.Minute)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	attempts, err = repo.GetLoginAttempts(ctx, "account:b")
+	require.NoError(t, err)
+	assert.Equal(t, 8, attempts.Failures)
+}
+
+func TestMemoryLoginAttemptRepository(t *testing.T) {
+	testLoginAttemptRepository(t, memory_repository.NewLoginAttemptRepository())
+}
+
+func TestGormLoginAttemptRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "attempts.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	testLoginAttemptRepository(t, gorm_repository.NewLoginAttemptRepository(db, 5*time.Second))
+}
+
+func newLockoutAuthService(t *testing.T, policy domain.LockoutPolicy) ports.AuthService {
+	users := memory_repository.NewUserRepository()
+	require.NoError(t, users.CreateUser(context.Background(), &domain.User{Name: "Alice", Email: "alice@example.com", Password: "securepassword"}))
+	return services.NewAuthService(users, memory_repository.NewRefreshTokenRepository(), memory_repository.NewLoginAttemptRepository(), nil, nil, nil, testKeys, services.AuthConfig{
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Lockout:         policy,
+	})
+}
+
+func TestAuthService_ProgressiveDelay(t *testing.T) {
+	ctx := context.Background()
+	authService := newLockoutAuthService(t, domain.LockoutPolicy{BaseDelay: time.Minute, MaxDelay: time.Hour, FailureWindow: time.Hour})
+
+	_, err := authService.LoginUser(ctx, "alice@example.com", "wrong")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	// Even the right password has to wait out the delay
+	_, err = authService.LoginUser(ctx, "alice@example.com", "securepassword")
+	assert.ErrorIs(t, err, domain.ErrLoginThrottled)
+	var retryErr *domain.RetryError
+	require.True(t, errors.As(err, &retryErr))
+	assert.InDelta(t, time.Minute.Seconds(), retryErr.RetryAfter.Seconds(), 5)
+}
+
+func TestAuthService_LockoutPerIP(t *testing.T) {
+	policy := testLockoutPolicy
+	policy.MaxAccountFailures = 0
+	policy.MaxIPFailures = 3
+	authService := newLockoutAuthService(t, policy)
+	attacker := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "203.0.113.7"})
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := authService.LoginUser(attacker, email, "guess")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err := authService.LoginUser(attacker, "alice@example.com", "securepassword")
+	assert.ErrorIs(t, err, domain.ErrLoginThrottled)
+
+	// Someone else is not affected by the locked address
+	other := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "198.51.100.1"})
+	_, err = authService.LoginUser(other, "alice@example.com", "securepassword")
+	assert.NoError(t, err)
+}
+
+func TestLoginRoutes_LockoutLooksTheSameForUnknownEmails(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+
+	var firstFailure map[string]interface{}
+	for _, email := range []string{"alice@example.com", "ghost@example.com"} {
+		for i := 0; i < testLockoutPolicy.MaxAccountFailures; i++ {
+			status, body := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": email, "password": "Wrong1234"})
+			assert.Equal(t, fiber.StatusUnauthorized, status)
+			assert.Equal(t, "invalid_credentials", body["code"])
+			delete(body, "request_id")
+			if firstFailure == nil {
+				firstFailure = body
+			}
+			assert.Equal(t, firstFailure, body)
+		}
+
+		req := httptest.NewRequest(fiber.MethodPost, "/login", strings.NewReader(`{"email":"`+email+`","password":"Secret123!"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.app.Test(req, -1)
+		require.NoError(t, err)
+		var body map[string]interface{}
+		require.NoError(t, jsonDecode(resp, &body))
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "too_many_attempts", body["code"])
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+}
+
+func TestLoginRoutes_AdminUnlock(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Alice", "alice@example.com")
+	_, adminToken := app.login("root@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+
+	for i := 0; i < testLockoutPolicy.MaxAccountFailures; i++ {
+		app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Wrong1234"})
+	}
+	status, _ := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	require.Equal(t, fiber.StatusTooManyRequests, status)
+
+	status, body := app.do(fiber.MethodDelete, "/users/"+aliceID+"/lockout", aliceToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, "insufficient_permission", body["code"])
+
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/lockout", adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	app.login("alice@example.com")
+}
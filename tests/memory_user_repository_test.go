@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryUserRepository_CreateAndFetchUser(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewUserRepository()
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "secret"}
+	assert.NoError(t, repo.CreateUser(ctx, user))
+	assert.False(t, user.ID.IsZero())
+	assert.False(t, user.CreatedAt.IsZero())
+
+	res, err := repo.GetUserByID(ctx, user.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", res.Name)
+	assert.Empty(t, res.Password)
+
+	login, err := repo.GetUserLoginByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(login.Password), []byte("secret")))
+
+	_, err = repo.GetUserByEmail(ctx, "nobody@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestMemoryUserRepository_UniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewUserRepository()
+
+	assert.NoError(t, repo.CreateUser(ctx, &domain.User{Name: "Alice", Email: "alice@example.com", Password: "a"}))
+	err := repo.CreateUser(ctx, &domain.User{Name: "Alice 2", Email: "alice@example.com", Password: "b"})
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+
+	bob := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "c"}
+	assert.NoError(t, repo.CreateUser(ctx, bob))
+	_, err = repo.UpdateUserByID(ctx, bob.ID.Hex(), bob.Version, domain.UserUpdate{Email: ptr("alice@example.com")})
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+}
+
+func TestMemoryUserRepository_ConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewUserRepository()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := repo.CreateUser(ctx, &domain.User{Name: "Carol", Email: "carol@example.com", Password: "pw"}); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+	page, err := repo.GetAllUsers(ctx, domain.UserQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+}
+
+func TestMemoryUserRepository_UpdateAndDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewUserRepository()
+
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123"}
+	assert.NoError(t, repo.CreateUser(ctx, user))
+
+	updated, err := repo.UpdateUserByID(ctx, user.ID.Hex(), user.Version, domain.UserUpdate{Name: ptr("Robert"), Email: ptr("robert@example.com")})
+	assert.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Empty(t, updated.Password)
+
+	_, err = repo.GetUserByEmail(ctx, "bob@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	assert.NoError(t, repo.DeleteUserByID(ctx, user.ID.Hex()))
+	_, err = repo.GetUserByID(ctx, user.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestMemoryUserRepository_GetAllUsersQuery(t *testing.T) {
+	testUserQueries(t, memory_repository.NewUserRepository())
+}
+
+func TestMemoryUserRepository_InvalidID(t *testing.T) {
+	repo := memory_repository.NewUserRepository()
+
+	_, err := repo.GetUserByID(context.Background(), "not-an-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+	assert.ErrorIs(t, repo.DeleteUserByID(context.Background(), "not-an-id"), domain.ErrInvalidUserID)
+}
@@ -0,0 +1,311 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/base32"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMFAConfig = services.MFAConfig{Issuer: "golang-rest-test", RecoveryCodeCount: 4}
+
+// totpCode is what an authenticator app shows for secret, steps periods from now.
+func totpCode(t *testing.T, secret string, steps int64) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+	return domain.TOTPCode(key, domain.TOTPStep(time.Now())+steps)
+}
+
+// enrollMFA turns MFA on for the user and returns the TOTP secret and the recovery codes.
+func (a *testApp) enrollMFA(id string, token string) (string, []string) {
+	status, body := a.do(fiber.MethodPost, "/users/"+id+"/mfa/totp", token, nil)
+	require.Equal(a.t, fiber.StatusCreated, status)
+	secret := body["secret"].(string)
+	assert.True(a.t, strings.HasPrefix(body["provisioning_uri"].(string), "otpauth://totp/golang-rest-test:"))
+
+	status, body = a.do(fiber.MethodPost, "/users/"+id+"/mfa/totp/confirm", token, fiber.Map{"code": totpCode(a.t, secret, 0)})
+	require.Equal(a.t, fiber.StatusOK, status)
+	var codes []string
+	for _, code := range body["recovery_codes"].([]interface{}) {
+		codes = append(codes, code.(string))
+	}
+	return secret, codes
+}
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for unix, code := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		assert.Equal(t, code, domain.TOTPCode(key, domain.TOTPStep(time.Unix(unix, 0))))
+	}
+}
+
+func TestMFA_LoginTakesTwoSteps(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+
+	status, body := app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/totp", aliceToken, nil)
+	require.Equal(t, fiber.StatusCreated, status)
+	secret := body["secret"].(string)
+	status, body = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/totp/confirm", aliceToken, fiber.Map{"code": "000000"})
+	if totpCode(t, secret, 0) != "000000" {
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		assert.Equal(t, "invalid_mfa_code", body["code"])
+	}
+	code := totpCode(t, secret, 0)
+	status, body = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/totp/confirm", aliceToken, fiber.Map{"code": code})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, body["recovery_codes"], testMFAConfig.RecoveryCodeCount)
+
+	// The password alone only gets a challenge
+	status, body = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, body["mfa_required"])
+	assert.Nil(t, body["token"])
+	mfaToken := body["mfa_token"].(string)
+
+	// The code that confirmed the enrollment cannot be replayed
+	status, body = app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": mfaToken, "code": code})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_mfa_code", body["code"])
+	status, body = app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": mfaToken + "x", "code": totpCode(t, secret, 1)})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_token", body["code"])
+
+	status, body = app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": mfaToken, "code": totpCode(t, secret, 1)})
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, body["token"].(string), nil)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, body = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/totp", aliceToken, nil)
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Equal(t, "mfa_already_enabled", body["code"])
+}
+
+func TestMFA_RefusesTokensSignedForAnotherPurpose(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+
+	// The test app signs verification links and MFA challenges with the same secret
+	token := verificationToken(t, app.notifier.last())
+	status, body := app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": token, "code": "123456"})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_token", body["code"])
+}
+
+func TestMFA_RecoveryCodesWorkOnce(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	_, codes := app.enrollMFA(aliceID, aliceToken)
+
+	for _, want := range []int{fiber.StatusOK, fiber.StatusUnauthorized} {
+		_, body := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+		status, _ := app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": body["mfa_token"], "code": strings.ToUpper(codes[0])})
+		assert.Equal(t, want, status)
+	}
+
+	// New codes replace the old ones
+	status, body := app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/recovery-codes", aliceToken, fiber.Map{"code": codes[1]})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, body["recovery_codes"], testMFAConfig.RecoveryCodeCount)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/mfa", aliceToken, fiber.Map{"code": codes[2]})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	newCode := body["recovery_codes"].([]interface{})[0]
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/mfa", aliceToken, fiber.Map{"code": newCode})
+	require.Equal(t, fiber.StatusOK, status)
+	status, body = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.NotEmpty(t, body["token"])
+}
+
+func TestMFA_WrongCodesLockTheAccount(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	app.enrollMFA(aliceID, aliceToken)
+
+	_, body := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	mfaToken := body["mfa_token"]
+	for i := 0; i < testLockoutPolicy.MaxAccountFailures; i++ {
+		status, _ := app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": mfaToken, "code": "abcde-abcde"})
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	}
+	status, body := app.do(fiber.MethodPost, "/login/mfa", "", fiber.Map{"mfa_token": mfaToken, "code": "abcde-abcde"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "too_many_attempts", body["code"])
+}
+
+func TestMFA_WrongCodesOfLoggedInUsersLockTheAccount(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	app.enrollMFA(aliceID, aliceToken)
+
+	// A stolen session cannot guess its way to turning MFA off or to new recovery codes
+	for i := 0; i < testLockoutPolicy.MaxAccountFailures; i++ {
+		path, method := "/users/"+aliceID+"/mfa", fiber.MethodDelete
+		if i%2 == 1 {
+			path, method = "/users/"+aliceID+"/mfa/recovery-codes", fiber.MethodPost
+		}
+		status, body := app.do(method, path, aliceToken, fiber.Map{"code": "abcde-abcde"})
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		assert.Equal(t, "invalid_mfa_code", body["code"])
+	}
+	status, body := app.do(fiber.MethodDelete, "/users/"+aliceID+"/mfa", aliceToken, fiber.Map{"code": "abcde-abcde"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "too_many_attempts", body["code"])
+
+	status, _ = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+}
+
+func TestMFA_OnlyAdminsResetSomeoneElses(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Alice", "alice@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	_, bobToken := app.login("bob@example.com")
+	app.enrollMFA(aliceID, aliceToken)
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+aliceID+"/mfa", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, body := app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/totp", adminToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, "not_owner", body["code"])
+
+	// Even an admin needs Alice's code to turn hers off, a reset is its own permission
+	status, body = app.do(fiber.MethodDelete, "/users/"+aliceID+"/mfa", adminToken, fiber.Map{"code": "123456"})
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, "not_owner", body["code"])
+	status, _ = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/reset", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// An API key is refused even with the scope
+	status, body = app.do(fiber.MethodPost, "/api-keys", adminToken, fiber.Map{"name": "support", "scopes": []string{"mfa:reset"}})
+	require.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, fiber.StatusForbidden, app.doWithHeader(fiber.MethodPost, "/users/"+aliceID+"/mfa/reset", "X-API-Key", body["key"].(string)))
+
+	// Alice lost her phone and her codes
+	status, _ = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/reset", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	app.login("alice@example.com")
+	events := app.auditEvents(adminToken, "action=mfa.reset")
+	require.Len(t, events, 2)
+	assert.Equal(t, "success", events[0]["outcome"])
+	assert.Equal(t, "failure", events[1]["outcome"])
+	assert.Equal(t, "api_key_not_allowed", events[1]["reason"])
+
+	status, body = app.do(fiber.MethodPost, "/users/"+aliceID+"/mfa/reset", adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "mfa_not_enrolled", body["code"])
+}
+
+func TestAuthService_RequireAdminMFA(t *testing.T) {
+	ctx := context.Background()
+	userRepository := memory_repository.NewUserRepository()
+	admin := &domain.User{Name: "Root", Email: "root@example.com", Password: "securepassword", Roles: []string{domain.RoleUser, domain.RoleAdmin}}
+	require.NoError(t, userRepository.CreateUser(ctx, admin))
+	mfaRepository := memory_repository.NewMFARepository()
+	config := testAuthConfig
+	config.RequireAdminMFA = true
+	config.MFAChallengeSecret = []byte("test-secret")
+	config.MFAChallengeTTL = time.Minute
+	authService := services.NewAuthService(userRepository, memory_repository.NewRefreshTokenRepository(), nil, nil, mfaRepository, nil, testKeys, config)
+	mfaService := services.NewMFAService(userRepository, mfaRepository, authService, testMFAConfig)
+
+	roles := func(tokens *ports.TokenPair) []interface{} {
+		claims := jwt.MapClaims{}
+		_, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, claims)
+		require.NoError(t, err)
+		return claims["roles"].([]interface{})
+	}
+
+	// Without a second factor the admin can log in, but only as a user, to enroll one
+	tokens, err := authService.LoginUser(ctx, admin.Email, "securepassword")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{domain.RoleUser}, roles(tokens))
+
+	ownerCtx := domain.WithPrincipal(ctx, &domain.Principal{UserID: admin.ID.Hex()})
+	enrollment, err := mfaService.EnrollTOTP(ownerCtx, admin.ID.Hex())
+	require.NoError(t, err)
+	_, err = mfaService.ConfirmTOTP(ownerCtx, admin.ID.Hex(), totpCode(t, enrollment.Secret, 0))
+	require.NoError(t, err)
+
+	_, err = authService.LoginUser(ctx, admin.Email, "securepassword")
+	var challenge *domain.MFARequiredError
+	require.ErrorAs(t, err, &challenge)
+	assert.ErrorIs(t, err, domain.ErrMFARequired)
+	tokens, err = authService.VerifyMFA(ctx, challenge.Token, totpCode(t, enrollment.Secret, 1))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{domain.RoleUser, domain.RoleAdmin}, roles(tokens))
+}
+
+func testMFARepository(t *testing.T, repo ports.MFARepositoryInterface) {
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	_, err := repo.GetMFA(ctx, userID.Hex())
+	assert.ErrorIs(t, err, domain.ErrMFANotEnrolled)
+
+	require.NoError(t, repo.SaveMFA(ctx, &domain.MFA{UserID: userID, Secret: "pending", CreatedAt: time.Now()}))
+	enabledAt := time.Now()
+	require.NoError(t, repo.SaveMFA(ctx, &domain.MFA{UserID: userID, Secret: "secret", RecoveryCodes: []string{"hash-1", "hash-2"}, LastUsedStep: 10, EnabledAt: &enabledAt, CreatedAt: time.Now()}))
+	stored, err := repo.GetMFA(ctx, userID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", stored.Secret)
+	assert.True(t, stored.IsEnabled())
+	assert.ElementsMatch(t, []string{"hash-1", "hash-2"}, stored.RecoveryCodes)
+
+	for step, want := range map[int64]bool{10: false, 9: false} {
+		used, err := repo.UseTOTPStep(ctx, userID.Hex(), step)
+		require.NoError(t, err)
+		assert.Equal(t, want, used)
+	}
+	used, err := repo.UseTOTPStep(ctx, userID.Hex(), 11)
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.UseTOTPStep(ctx, userID.Hex(), 11)
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	used, err = repo.UseRecoveryCode(ctx, userID.Hex(), "hash-1")
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.UseRecoveryCode(ctx, userID.Hex(), "hash-1")
+	require.NoError(t, err)
+	assert.False(t, used)
+	stored, err = repo.GetMFA(ctx, userID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-2"}, stored.RecoveryCodes)
+	assert.Equal(t, int64(11), stored.LastUsedStep)
+
+	require.NoError(t, repo.DeleteMFA(ctx, userID.Hex()))
+	_, err = repo.GetMFA(ctx, userID.Hex())
+	assert.ErrorIs(t, err, domain.ErrMFANotEnrolled)
+}
+
+func TestMemoryMFARepository(t *testing.T) {
+	testMFARepository(t, memory_repository.NewMFARepository())
+}
+
+func TestGormMFARepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "mfa.db"))
+	require.NoError(t, err)
+	testMFARepository(t, gorm_repository.NewMFARepository(db, time.Second))
+}
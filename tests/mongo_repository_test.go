@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang-rest/internal/adapters/outbound/mongo_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"os"
+	"testing"
+	"time"
+)
+
+const mongoTestTimeout = 5 * time.Second
+
+// newMongoDatabase gives the test its own database on the server at MONGO_URI, dropped afterwards.
+// The Mongo tests are skipped when MONGO_URI is unset.
+func newMongoDatabase(t *testing.T) *mongo.Database {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(mongoTestTimeout))
+	require.NoError(t, err)
+	// The repositories exit on a failed index build, so an unreachable server has to fail here
+	require.NoError(t, client.Ping(ctx, nil))
+
+	database := client.Database("golang_rest_test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		_ = database.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	return database
+}
+
+func newMongoUserRepository(t *testing.T) ports.UserRepositoryInterface {
+	return mongo_repository.NewUserRepository(newMongoDatabase(t).Collection("users"), mongoTestTimeout)
+}
+
+func TestMongoUserRepository_Queries(t *testing.T) {
+	testUserQueries(t, newMongoUserRepository(t))
+}
+
+func TestMongoUserRepository_SoftDelete(t *testing.T) {
+	testUserSoftDelete(t, newMongoUserRepository(t))
+}
+
+func TestMongoUserRepository_Versions(t *testing.T) {
+	testUserVersions(t, newMongoUserRepository(t))
+}
+
+func TestMongoUserRepository_MarkUserEmailVerified(t *testing.T) {
+	testMarkUserEmailVerified(t, newMongoUserRepository(t))
+}
+
+func TestMongoUserRepository_VersionOfOlderDocuments(t *testing.T) {
+	ctx := context.Background()
+	collection := newMongoDatabase(t).Collection("users")
+	repo := mongo_repository.NewUserRepository(collection, mongoTestTimeout)
+
+	// Users written before versioning have no version field, they count as version 0
+	id := primitive.NewObjectID()
+	_, err := collection.InsertOne(ctx, bson.M{"_id": id, "name": "Bob", "email": "bob@example.com", "password": "hash"})
+	require.NoError(t, err)
+
+	stored, err := repo.GetUserByID(ctx, id.Hex())
+	require.NoError(t, err)
+	assert.Zero(t, stored.Version)
+
+	updated, err := repo.UpdateUserByID(ctx, id.Hex(), 0, domain.UserUpdate{Name: ptr("Robert")})
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Equal(t, int64(1), updated.Version)
+
+	_, err = repo.UpdateUserByID(ctx, id.Hex(), 0, domain.UserUpdate{Name: ptr("Bobby")})
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+}
+
+func TestMongoRefreshTokenRepository(t *testing.T) {
+	testRefreshTokenRepository(t, mongo_repository.NewRefreshTokenRepository(newMongoDatabase(t).Collection("refresh_tokens"), mongoTestTimeout))
+}
+
+func TestMongoPasswordResetTokenRepository(t *testing.T) {
+	testPasswordResetTokenRepository(t, mongo_repository.NewPasswordResetTokenRepository(newMongoDatabase(t).Collection("password_reset_tokens"), mongoTestTimeout))
+}
+
+func TestMongoLoginAttemptRepository(t *testing.T) {
+	testLoginAttemptRepository(t, mongo_repository.NewLoginAttemptRepository(newMongoDatabase(t).Collection("login_attempts"), mongoTestTimeout))
+}
+
+func TestMongoAPIKeyRepository(t *testing.T) {
+	testAPIKeyRepository(t, mongo_repository.NewAPIKeyRepository(newMongoDatabase(t).Collection("api_keys"), mongoTestTimeout))
+}
+
+func TestMongoIdentityRepository(t *testing.T) {
+	testIdentityRepository(t, mongo_repository.NewIdentityRepository(newMongoDatabase(t).Collection("identities"), mongoTestTimeout))
+}
+
+func TestMongoMFARepository(t *testing.T) {
+	testMFARepository(t, mongo_repository.NewMFARepository(newMongoDatabase(t).Collection("mfa"), mongoTestTimeout))
+}
+
+func TestMongoSessionRepository(t *testing.T) {
+	testSessionRepository(t, mongo_repository.NewSessionRepository(newMongoDatabase(t).Collection("sessions"), mongoTestTimeout))
+}
+
+func TestMongoAuditRepository(t *testing.T) {
+	testAuditRepository(t, mongo_repository.NewAuditRepository(newMongoDatabase(t).Collection("audit_events"), mongoTestTimeout))
+}
+
+func TestMongoUserPurge(t *testing.T) {
+	database := newMongoDatabase(t)
+	testUserPurge(t, purgeRepositories{
+		users:         mongo_repository.NewUserRepository(database.Collection("users"), mongoTestTimeout),
+		identities:    mongo_repository.NewIdentityRepository(database.Collection("identities"), mongoTestTimeout),
+		mfa:           mongo_repository.NewMFARepository(database.Collection("mfa"), mongoTestTimeout),
+		sessions:      mongo_repository.NewSessionRepository(database.Collection("sessions"), mongoTestTimeout),
+		refreshTokens: mongo_repository.NewRefreshTokenRepository(database.Collection("refresh_tokens"), mongoTestTimeout),
+		resetTokens:   mongo_repository.NewPasswordResetTokenRepository(database.Collection("password_reset_tokens"), mongoTestTimeout),
+		loginAttempts: mongo_repository.NewLoginAttemptRepository(database.Collection("login_attempts"), mongoTestTimeout),
+	})
+}
@@ -0,0 +1,325 @@
+package repository_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	httpadapter "golang-rest/internal/adapters/inbound/http"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/jwtkeys"
+	"golang-rest/internal/infrastructure/oidc"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testClientID = "golang-rest-client"
+
+type mockProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// Nonce replaces the nonce of the login request when set, like a replayed id_token would.
+	Nonce string
+}
+
+type mockGrant struct {
+	challenge string
+	nonce     string
+	profile   mockProfile
+}
+
+// mockOIDCProvider is a minimal OpenID provider: discovery, a PKCE checking token endpoint and a JWKS.
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    jwtkeys.Key
+
+	mu     sync.Mutex
+	grants map[string]mockGrant
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := jwtkeys.GenerateKey(jwtkeys.EdDSA)
+	require.NoError(t, err)
+	m := &mockOIDCProvider{t: t, key: key, grants: make(map[string]mockGrant)}
+
+	mux := nethttp.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		writeJSON(w, jwtkeys.JWKS{Keys: []jwtkeys.JWK{m.key.JWK()}})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize plays the user approving the login at the provider and returns the code and state for the callback.
+func (m *mockOIDCProvider) authorize(location string, profile mockProfile) (string, string) {
+	u, err := url.Parse(location)
+	require.NoError(m.t, err)
+	query := u.Query()
+	require.Equal(m.t, m.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(m.t, "code", query.Get("response_type"))
+	require.Equal(m.t, "S256", query.Get("code_challenge_method"))
+	require.Equal(m.t, testClientID, query.Get("client_id"))
+
+	code := primitive.NewObjectID().Hex()
+	m.mu.Lock()
+	m.grants[code] = mockGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), profile: profile}
+	m.mu.Unlock()
+	return code, query.Get("state")
+}
+
+func (m *mockOIDCProvider) token(w nethttp.ResponseWriter, r *nethttp.Request) {
+	require.NoError(m.t, r.ParseForm())
+	m.mu.Lock()
+	grant, ok := m.grants[r.PostForm.Get("code")]
+	delete(m.grants, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.WriteHeader(nethttp.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := grant.nonce
+	if grant.profile.Nonce != "" {
+		nonce = grant.profile.Nonce
+	}
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            grant.profile.Subject,
+		"aud":            testClientID,
+		"exp":            now.Add(time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          grant.profile.Email,
+		"email_verified": grant.profile.EmailVerified,
+	})
+	idToken.Header["kid"] = m.key.ID
+	signed, err := idToken.SignedString(m.key.Private)
+	require.NoError(m.t, err)
+
+	writeJSON(w, map[string]interface{}{"access_token": "provider-token", "token_type": "Bearer", "expires_in": 60, "id_token": signed})
+}
+
+func writeJSON(w nethttp.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type oidcTestApp struct {
+	*testApp
+	provider    *mockOIDCProvider
+	userService ports.UserService
+	identities  ports.IdentityRepositoryInterface
+}
+
+func newOIDCTestApp(t *testing.T) *oidcTestApp {
+	provider := newMockOIDCProvider(t)
+	identityProvider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:      provider.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/auth/mock/callback",
+	}, provider.server.Client())
+	require.NoError(t, err)
+
+	userRepository := memory_repository.NewUserRepository()
+	identities := memory_repository.NewIdentityRepository()
+	authService := services.NewAuthService(userRepository, memory_repository.NewRefreshTokenRepository(), nil, nil, nil, nil, testKeys, services.AuthConfig{
+		Issuer:          testTokenValidation.Issuer,
+		Audience:        []string{testTokenValidation.Audience},
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	userService := services.NewUserService(userRepository, nil, authService, services.UserConfig{PasswordPolicy: domain.DefaultPasswordPolicy})
+	oidcService := services.NewOIDCService(userRepository, identities, authService, map[string]ports.IdentityProvider{"mock": identityProvider}, services.OIDCConfig{
+		SessionSecret: []byte("test-secret"),
+		SessionTTL:    time.Minute,
+	})
+
+	app := fiber.New()
+	httpadapter.Setup(app, userService, authService, nil, nil, nil, oidcService, nil, nil, nil, httpadapter.AccessTokens{Keys: testKeys, Validation: testTokenValidation}, httpadapter.RateLimits{})
+	return &oidcTestApp{testApp: &testApp{t: t, app: app}, provider: provider, userService: userService, identities: identities}
+}
+
+// startLogin returns where the browser is sent and the session cookie it gets on the way.
+func (a *oidcTestApp) startLogin() (string, *nethttp.Cookie) {
+	resp, err := a.app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/mock/login", nil), -1)
+	require.NoError(a.t, err)
+	require.Equal(a.t, fiber.StatusFound, resp.StatusCode)
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "oidc_session" {
+			assert.True(a.t, cookie.HttpOnly)
+			return resp.Header.Get("Location"), cookie
+		}
+	}
+	a.t.Fatal("login did not set the session cookie")
+	return "", nil
+}
+
+func (a *oidcTestApp) callback(code string, state string, cookie *nethttp.Cookie) (int, map[string]interface{}) {
+	req := httptest.NewRequest(fiber.MethodGet, "/auth/mock/callback?"+url.Values{"code": {code}, "state": {state}}.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	resp, err := a.app.Test(req, -1)
+	require.NoError(a.t, err)
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func (a *oidcTestApp) loginAs(profile mockProfile) (int, map[string]interface{}) {
+	location, cookie := a.startLogin()
+	code, state := a.provider.authorize(location, profile)
+	return a.callback(code, state, cookie)
+}
+
+func TestOIDCLogin_CreatesAndLinksUser(t *testing.T) {
+	app := newOIDCTestApp(t)
+	profile := mockProfile{Subject: "subject-1", Email: "carol@example.com", EmailVerified: true}
+
+	status, body := app.loginAs(profile)
+	require.Equal(t, fiber.StatusOK, status)
+	token := body["token"].(string)
+	assert.NotEmpty(t, body["refresh_token"])
+
+	identity, err := app.identities.GetIdentity(context.Background(), "mock", "subject-1")
+	require.NoError(t, err)
+	status, body = app.do(fiber.MethodGet, "/users/"+identity.UserID.Hex(), token, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "carol@example.com", body["email"])
+	assert.Equal(t, "carol", body["name"])
+	assert.Equal(t, true, body["email_verified"])
+
+	// The next login finds the same account through the link
+	status, _ = app.loginAs(profile)
+	require.Equal(t, fiber.StatusOK, status)
+	page, err := app.userService.GetAllUsers(context.Background(), domain.UserQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+}
+
+func TestOIDCLogin_LinksExistingEmailOnlyWhenVerified(t *testing.T) {
+	app := newOIDCTestApp(t)
+	user, err := app.userService.RegisterUser(context.Background(), ports.RegisterUserInput{Name: "Dave", Email: "dave@example.com", Password: "Secret123!"})
+	require.NoError(t, err)
+
+	status, body := app.loginAs(mockProfile{Subject: "subject-2", Email: "dave@example.com"})
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Equal(t, "email_already_exists", body["code"])
+	_, err = app.identities.GetIdentity(context.Background(), "mock", "subject-2")
+	assert.ErrorIs(t, err, domain.ErrIdentityNotFound)
+
+	status, _ = app.loginAs(mockProfile{Subject: "subject-2", Email: "dave@example.com", EmailVerified: true})
+	require.Equal(t, fiber.StatusOK, status)
+	identity, err := app.identities.GetIdentity(context.Background(), "mock", "subject-2")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, identity.UserID)
+}
+
+func TestOIDCLogin_RejectsForgedCallbacks(t *testing.T) {
+	app := newOIDCTestApp(t)
+	profile := mockProfile{Subject: "subject-3", Email: "erin@example.com", EmailVerified: true}
+
+	location, cookie := app.startLogin()
+	code, state := app.provider.authorize(location, profile)
+	status, body := app.callback(code, state+"x", cookie)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_state", body["code"])
+
+	location, _ = app.startLogin()
+	code, state = app.provider.authorize(location, profile)
+	status, body = app.callback(code, state, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_state", body["code"])
+
+	// A session from another login does not match this state either
+	_, otherCookie := app.startLogin()
+	location, _ = app.startLogin()
+	code, state = app.provider.authorize(location, profile)
+	status, _ = app.callback(code, state, otherCookie)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	profile.Nonce = "replayed"
+	status, body = app.loginAs(profile)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "oidc_login_failed", body["code"])
+
+	_, err := app.identities.GetIdentity(context.Background(), "mock", "subject-3")
+	assert.ErrorIs(t, err, domain.ErrIdentityNotFound)
+}
+
+func TestOIDCLogin_UnknownProvider(t *testing.T) {
+	app := newOIDCTestApp(t)
+
+	status, body := app.do(fiber.MethodGet, "/auth/nope/login", "", nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "unknown_provider", body["code"])
+}
+
+func testIdentityRepository(t *testing.T, repo ports.IdentityRepositoryInterface) {
+	ctx := context.Background()
+	identity := &domain.Identity{UserID: primitive.NewObjectID(), Provider: "mock", Subject: "subject-1", Email: "a@example.com", CreatedAt: time.Now()}
+	require.NoError(t, repo.CreateIdentity(ctx, identity))
+	assert.False(t, identity.ID.IsZero())
+
+	duplicate := &domain.Identity{UserID: primitive.NewObjectID(), Provider: "mock", Subject: "subject-1", CreatedAt: time.Now()}
+	assert.ErrorIs(t, repo.CreateIdentity(ctx, duplicate), domain.ErrIdentityLinked)
+	// The same subject at another provider is another identity
+	require.NoError(t, repo.CreateIdentity(ctx, &domain.Identity{UserID: identity.UserID, Provider: "other", Subject: "subject-1", CreatedAt: time.Now()}))
+
+	stored, err := repo.GetIdentity(ctx, "mock", "subject-1")
+	require.NoError(t, err)
+	assert.Equal(t, identity.UserID, stored.UserID)
+	assert.Equal(t, "a@example.com", stored.Email)
+
+	_, err = repo.GetIdentity(ctx, "mock", "missing")
+	assert.ErrorIs(t, err, domain.ErrIdentityNotFound)
+
+	require.NoError(t, repo.DeleteIdentity(ctx, "mock", "subject-1"))
+	_, err = repo.GetIdentity(ctx, "mock", "subject-1")
+	assert.ErrorIs(t, err, domain.ErrIdentityNotFound)
+	_, err = repo.GetIdentity(ctx, "other", "subject-1")
+	assert.NoError(t, err)
+}
+
+func TestMemoryIdentityRepository(t *testing.T) {
+	testIdentityRepository(t, memory_repository.NewIdentityRepository())
+}
+
+func TestGormIdentityRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "identities.db"))
+	require.NoError(t, err)
+	testIdentityRepository(t, gorm_repository.NewIdentityRepository(db, time.Second))
+}
@@ -0,0 +1,204 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/adapters/outbound/notifier"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []domain.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) last() domain.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.notifications[len(n.notifications)-1]
+}
+
+var resetTokenPattern = regexp.MustCompile(`token is (\S+)`)
+
+func newTestPasswordService(t *testing.T, ttl time.Duration) (ports.PasswordService, ports.AuthService, *recordingNotifier, *domain.User) {
+	userRepository := memory_repository.NewUserRepository()
+	user := &domain.User{Name: "Test User", Email: "testuser@example.com", Password: "securepassword"}
+	require.NoError(t, userRepository.CreateUser(context.Background(), user))
+
+	userNotifier := &recordingNotifier{}
+	authService := services.NewAuthService(userRepository, memory_repository.NewRefreshTokenRepository(), nil, nil, nil, nil, testKeys, testAuthConfig)
+	passwordService := services.NewPasswordService(userRepository, memory_repository.NewPasswordResetTokenRepository(), userNotifier, authService, authService, services.PasswordConfig{ResetTokenTTL: ttl})
+	return passwordService, authService, userNotifier, user
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	passwordService, authService, _, user := newTestPasswordService(t, time.Hour)
+
+	err := passwordService.ChangePassword(ctx, user.ID.Hex(), "wrong", "newpassword")
+	assert.ErrorIs(t, err, domain.ErrIncorrectPassword)
+	err = passwordService.ChangePassword(ctx, user.ID.Hex(), "securepassword", "")
+	assert.ErrorIs(t, err, domain.ErrMissingFields)
+	err = passwordService.ChangePassword(ctx, primitive.NewObjectID().Hex(), "securepassword", "newpassword")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	require.NoError(t, passwordService.ChangePassword(ctx, user.ID.Hex(), "securepassword", "newpassword"))
+
+	_, err = authService.LoginUser(ctx, user.Email, "securepassword")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, err = authService.LoginUser(ctx, user.Email, "newpassword")
+	assert.NoError(t, err)
+}
+
+func TestPasswordService_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	passwordService, authService, userNotifier, user := newTestPasswordService(t, time.Hour)
+
+	// Unknown emails succeed silently
+	require.NoError(t, passwordService.RequestPasswordReset(ctx, "nobody@example.com"))
+	assert.Empty(t, userNotifier.notifications)
+
+	require.NoError(t, passwordService.RequestPasswordReset(ctx, user.Email))
+	notification := userNotifier.last()
+	assert.Equal(t, user.Email, notification.To)
+	match := resetTokenPattern.FindStringSubmatch(notification.Body)
+	require.Len(t, match, 2)
+	token := match[1]
+
+	assert.ErrorIs(t, passwordService.ResetPassword(ctx, "bogus", "newpassword"), domain.ErrInvalidToken)
+	require.NoError(t, passwordService.ResetPassword(ctx, token, "newpassword"))
+	assert.ErrorIs(t, passwordService.ResetPassword(ctx, token, "otherpassword"), domain.ErrInvalidToken)
+
+	_, err := authService.LoginUser(ctx, user.Email, "newpassword")
+	assert.NoError(t, err)
+}
+
+func TestPasswordService_ResetTokenExpires(t *testing.T) {
+	ctx := context.Background()
+	passwordService, _, userNotifier, user := newTestPasswordService(t, -time.Second)
+
+	require.NoError(t, passwordService.RequestPasswordReset(ctx, user.Email))
+	token := resetTokenPattern.FindStringSubmatch(userNotifier.last().Body)[1]
+	assert.ErrorIs(t, passwordService.ResetPassword(ctx, token, "newpassword"), domain.ErrInvalidToken)
+}
+
+func testPasswordResetTokenRepository(t *testing.T, repo ports.PasswordResetTokenRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now()
+
+	token := &domain.PasswordResetToken{UserID: primitive.NewObjectID(), TokenHash: "hash-1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repo.CreatePasswordResetToken(ctx, token))
+
+	stored, err := repo.GetPasswordResetTokenByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, token.UserID, stored.UserID)
+	assert.Nil(t, stored.UsedAt)
+
+	_, err = repo.GetPasswordResetTokenByHash(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	used, err := repo.MarkPasswordResetTokenUsed(ctx, stored.ID.Hex(), now)
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.MarkPasswordResetTokenUsed(ctx, stored.ID.Hex(), now)
+	require.NoError(t, err)
+	assert.False(t, used)
+}
+
+func TestMemoryPasswordResetTokenRepository(t *testing.T) {
+	testPasswordResetTokenRepository(t, memory_repository.NewPasswordResetTokenRepository())
+}
+
+func TestGormPasswordResetTokenRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "reset.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	testPasswordResetTokenRepository(t, gorm_repository.NewPasswordResetTokenRepository(db, 5*time.Second))
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	fileNotifier := notifier.NewFileNotifier(path)
+
+	require.NoError(t, fileNotifier.Notify(context.Background(), domain.Notification{To: "a@example.com", Subject: "One", Body: "first"}))
+	require.NoError(t, fileNotifier.Notify(context.Background(), domain.Notification{To: "b@example.com", Subject: "Two", Body: "second"}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"to":"a@example.com"`)
+	assert.Contains(t, lines[1], `"subject":"Two"`)
+}
+
+func TestPasswordRoutes(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	app.register("Bob", "bob@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	bobID, _ := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodPut, "/users/"+bobID+"/password", aliceToken, fiber.Map{"current_password": "Secret123!", "new_password": "Hijacked1!"})
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodPut, "/users/"+aliceID+"/password", aliceToken, fiber.Map{"current_password": "nope", "new_password": "Changed123!"})
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodPut, "/users/"+aliceID+"/password", aliceToken, fiber.Map{"current_password": "Secret123!", "new_password": "Changed123!"})
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// Known and unknown emails get the same answer
+	status, unknown := app.do(fiber.MethodPost, "/password/forgot", "", fiber.Map{"email": "nobody@example.com"})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	status, known := app.do(fiber.MethodPost, "/password/forgot", "", fiber.Map{"email": "bob@example.com"})
+	assert.Equal(t, fiber.StatusAccepted, status)
+	assert.Equal(t, unknown, known)
+
+	token := resetTokenPattern.FindStringSubmatch(app.notifier.last().Body)[1]
+	status, _ = app.do(fiber.MethodPost, "/password/reset", "", fiber.Map{"token": token, "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusOK, status)
+	status, body := app.do(fiber.MethodPost, "/password/reset", "", fiber.Map{"token": token, "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_token", body["code"])
+}
+
+func TestPasswordRoutes_WrongCurrentPasswordsLockTheAccount(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+
+	// A stolen session cannot guess the password it would need to log in elsewhere
+	for i := 0; i < testLockoutPolicy.MaxAccountFailures; i++ {
+		status, body := app.do(fiber.MethodPut, "/users/"+aliceID+"/password", aliceToken, fiber.Map{"current_password": "Guess123!", "new_password": "Changed123!"})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		assert.Equal(t, "incorrect_password", body["code"])
+	}
+	status, body := app.do(fiber.MethodPut, "/users/"+aliceID+"/password", aliceToken, fiber.Map{"current_password": "Secret123!", "new_password": "Changed123!"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "too_many_attempts", body["code"])
+
+	status, _ = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+}
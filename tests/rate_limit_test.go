@@ -0,0 +1,168 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	httpadapter "golang-rest/internal/adapters/inbound/http"
+	"golang-rest/internal/infrastructure/middleware"
+	"golang-rest/internal/infrastructure/ratelimit"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_ParsePolicy(t *testing.T) {
+	policy, err := ratelimit.ParsePolicy("token_bucket:100/1m")
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 100, Window: time.Minute}, policy)
+	assert.Equal(t, "100;w=60", policy.Header())
+
+	for _, value := range []string{"", "token_bucket", "token_bucket:100", "leaky:1/1m", "sliding_window:0/1m", "sliding_window:5/soon"} {
+		_, err := ratelimit.ParsePolicy(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func testTokenBucket(t *testing.T, store ratelimit.Store) {
+	ctx := context.Background()
+	policy := ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 3, Window: 3 * time.Second}
+	now := time.Now()
+
+	// The full burst is available at once, then one token per second comes back
+	for i := 2; i >= 0; i-- {
+		result, err := store.Allow(ctx, "bucket", policy, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result, err := store.Allow(ctx, "bucket", policy, now)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, time.Second.Seconds(), result.RetryAfter.Seconds(), 0.01)
+	assert.InDelta(t, (3 * time.Second).Seconds(), result.ResetAfter.Seconds(), 0.01)
+
+	result, err = store.Allow(ctx, "bucket", policy, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = store.Allow(ctx, "other", policy, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func testSlidingWindow(t *testing.T, store ratelimit.Store) {
+	ctx := context.Background()
+	policy := ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute}
+	now := time.Now()
+
+	result, err := store.Allow(ctx, "window", policy, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	result, err = store.Allow(ctx, "window", policy, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = store.Allow(ctx, "window", policy, now.Add(40*time.Second))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, (20 * time.Second).Seconds(), result.RetryAfter.Seconds(), 0.01)
+
+	// The first request has left the window
+	result, err = store.Allow(ctx, "window", policy, now.Add(61*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	t.Run("token bucket", func(t *testing.T) { testTokenBucket(t, ratelimit.NewMemoryStore()) })
+	t.Run("sliding window", func(t *testing.T) { testSlidingWindow(t, ratelimit.NewMemoryStore()) })
+}
+
+func newRedisRateLimitStore(t *testing.T) ratelimit.Store {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return ratelimit.NewRedisStore(client, "ratelimit:")
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	t.Run("token bucket", func(t *testing.T) { testTokenBucket(t, newRedisRateLimitStore(t)) })
+	t.Run("sliding window", func(t *testing.T) { testSlidingWindow(t, newRedisRateLimitStore(t)) })
+}
+
+func TestRateLimitMiddleware_HeadersAnd429(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "test",
+		Policy: ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute},
+		Store:  ratelimit.NewMemoryStore(),
+		Key:    middleware.KeyByAPIKey("X-API-Key"),
+	}), func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	send := func(apiKey string) (int, map[string]string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		headers := map[string]string{}
+		for _, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", fiber.HeaderRetryAfter} {
+			headers[name] = resp.Header.Get(name)
+		}
+		return resp.StatusCode, headers
+	}
+
+	status, headers := send("key-a")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", headers["RateLimit-Limit"])
+	assert.Equal(t, "1", headers["RateLimit-Remaining"])
+	assert.Equal(t, "60", headers["RateLimit-Reset"])
+	assert.Equal(t, "2;w=60", headers["RateLimit-Policy"])
+
+	send("key-a")
+	status, headers = send("key-a")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "0", headers["RateLimit-Remaining"])
+	assert.Equal(t, "60", headers[fiber.HeaderRetryAfter])
+
+	// Another key has its own quota
+	status, _ = send("key-b")
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestRateLimitRoutes_AuthGroupIsLimited(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	app := newLimitedTestApp(t, httpadapter.RateLimits{
+		Auth: middleware.RateLimit(middleware.RateLimitConfig{
+			Name:   "auth",
+			Policy: ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute},
+			Store:  store,
+		}),
+		Read: middleware.RateLimit(middleware.RateLimitConfig{
+			Name:   "read",
+			Policy: ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 100, Window: time.Minute},
+			Store:  store,
+			Key:    middleware.KeyByUser,
+		}),
+	})
+	app.register("Alice", "alice@example.com")
+	aliceID, token := app.login("alice@example.com")
+
+	status, body := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "rate_limited", body["code"])
+
+	// Reads have their own, looser quota
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, token, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+}
@@ -0,0 +1,239 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	httpadapter "golang-rest/internal/adapters/inbound/http"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/audit"
+	"golang-rest/internal/infrastructure/middleware"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testApp struct {
+	t        *testing.T
+	app      *fiber.App
+	notifier *recordingNotifier
+	// The services behind the HTTP app, for grpcClient to serve as well
+	userService       ports.UserService
+	apiKeyService     ports.APIKeyService
+	revocations       ports.TokenRevocationRepositoryInterface
+	sessionRepository ports.SessionRepositoryInterface
+}
+
+func newTestApp(t *testing.T, adminEmails ...string) *testApp {
+	return newLimitedTestApp(t, httpadapter.RateLimits{}, adminEmails...)
+}
+
+func newLimitedTestApp(t *testing.T, limits httpadapter.RateLimits, adminEmails ...string) *testApp {
+	userRepository := memory_repository.NewUserRepository()
+	userNotifier := &recordingNotifier{}
+	emailVerificationService := services.NewEmailVerificationService(userRepository, userNotifier, services.EmailVerificationConfig{
+		Secret:    []byte("test-secret"),
+		TokenTTL:  time.Hour,
+		VerifyURL: "/verify-email",
+	})
+	revocations := memory_repository.NewTokenRevocationRepository()
+	mfaRepository := memory_repository.NewMFARepository()
+	refreshTokenRepository := memory_repository.NewRefreshTokenRepository()
+	sessionRepository := memory_repository.NewSessionRepository()
+	authService := services.NewAuthService(userRepository, refreshTokenRepository, memory_repository.NewLoginAttemptRepository(), revocations, mfaRepository, sessionRepository, testKeys, services.AuthConfig{
+		Issuer:             testTokenValidation.Issuer,
+		Audience:           []string{testTokenValidation.Audience},
+		AccessTokenTTL:     time.Minute,
+		RefreshTokenTTL:    time.Hour,
+		Lockout:            testLockoutPolicy,
+		MFAChallengeSecret: []byte("test-secret"),
+		MFAChallengeTTL:    time.Minute,
+	})
+	userService := services.NewUserService(userRepository, emailVerificationService, authService, services.UserConfig{
+		AdminEmails:    adminEmails,
+		PasswordPolicy: domain.DefaultPasswordPolicy,
+	})
+	passwordService := services.NewPasswordService(userRepository, memory_repository.NewPasswordResetTokenRepository(), userNotifier, authService, authService, services.PasswordConfig{
+		Policy:        domain.DefaultPasswordPolicy,
+		ResetTokenTTL: time.Hour,
+	})
+	sessionService := services.NewSessionService(sessionRepository, refreshTokenRepository, revocations, services.SessionConfig{
+		AccessTokenTTL: time.Minute,
+	})
+
+	app := fiber.New()
+	app.Use(middleware.RequestContext(context.Background()))
+	app.Use(middleware.RequestID())
+	app.Use(middleware.ClientInfo())
+	auditRepository := memory_repository.NewAuditRepository()
+	recorder := audit.NewRecorder(auditRepository)
+	auditedUserService := audit.NewUserService(userService, recorder)
+	apiKeyService := audit.NewAPIKeyService(services.NewAPIKeyService(memory_repository.NewAPIKeyRepository()), recorder)
+	httpadapter.Setup(app,
+		auditedUserService,
+		audit.NewAuthService(authService, recorder),
+		audit.NewPasswordService(passwordService, recorder),
+		audit.NewEmailVerificationService(emailVerificationService, recorder),
+		apiKeyService,
+		nil,
+		audit.NewMFAService(services.NewMFAService(userRepository, mfaRepository, authService, testMFAConfig), recorder),
+		audit.NewSessionService(sessionService, recorder),
+		services.NewAuditService(auditRepository),
+		httpadapter.AccessTokens{Keys: testKeys, Validation: testTokenValidation, Revocations: revocations, Sessions: sessionRepository}, limits)
+	return &testApp{t: t, app: app, notifier: userNotifier, userService: auditedUserService, apiKeyService: apiKeyService, revocations: revocations, sessionRepository: sessionRepository}
+}
+
+func (a *testApp) do(method string, path string, token string, body interface{}) (int, map[string]interface{}) {
+	status, _, result := a.doWithHeaders(method, path, token, nil, body)
+	return status, result
+}
+
+// doWithHeaders also sends header and returns the response headers.
+func (a *testApp) doWithHeaders(method string, path string, token string, header map[string]string, body interface{}) (int, nethttp.Header, map[string]interface{}) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(a.t, err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := a.app.Test(req, -1)
+	require.NoError(a.t, err)
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, resp.Header, result
+}
+
+// updateUser reads the user's ETag and sends it back with the PUT, like a well-behaved client.
+func (a *testApp) updateUser(userID string, token string, body interface{}) (int, map[string]interface{}) {
+	status, header, _ := a.doWithHeaders(fiber.MethodGet, "/users/"+userID, token, nil, nil)
+	require.Equal(a.t, fiber.StatusOK, status)
+	status, _, result := a.doWithHeaders(fiber.MethodPut, "/users/"+userID, token, map[string]string{"If-Match": header.Get("ETag")}, body)
+	return status, result
+}
+
+func (a *testApp) register(name string, email string) {
+	status, _ := a.do(fiber.MethodPost, "/register", "", fiber.Map{"name": name, "email": email, "password": "Secret123!"})
+	require.Equal(a.t, fiber.StatusCreated, status)
+}
+
+// login returns the user's id, read back from the access token, and the token itself.
+func (a *testApp) login(email string) (string, string) {
+	status, body := a.do(fiber.MethodPost, "/login", "", fiber.Map{"email": email, "password": "Secret123!"})
+	require.Equal(a.t, fiber.StatusOK, status)
+	token := body["token"].(string)
+
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(a.t, err)
+	return claims["sub"].(string), token
+}
+
+func TestRBAC_AdminOnlyRoutes(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	adminID, adminToken := app.login("root@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodGet, "/users/"+bobID, bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodGet, "/users", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+adminID, bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodPut, "/users/"+bobID+"/roles/admin", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, body := app.do(fiber.MethodGet, "/users", adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, float64(2), body["total"])
+
+	status, _ = app.do(fiber.MethodPut, "/users/"+bobID+"/roles/wizard", adminToken, nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	status, body = app.do(fiber.MethodPut, "/users/"+bobID+"/roles/admin", adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.ElementsMatch(t, []interface{}{"user", "admin"}, body["roles"])
+
+	// Granted roles reach the claims with the next token
+	_, bobToken = app.login("bob@example.com")
+	status, _ = app.do(fiber.MethodGet, "/users", bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, body = app.do(fiber.MethodDelete, "/users/"+bobID+"/roles/admin", adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, []interface{}{"user"}, body["roles"])
+}
+
+func TestRBAC_UnknownRoutesAreNotFound(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Bob", "bob@example.com")
+	_, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodGet, "/no-such-route", "", nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	status, _ = app.do(fiber.MethodGet, "/no-such-route", bobToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	status, _ = app.do(fiber.MethodGet, "/users", "", nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
+
+func TestRBAC_OwnershipOnUserRoutes(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Alice", "alice@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	aliceID, aliceToken := app.login("alice@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	// Bob owns his record
+	status, body := app.do(fiber.MethodGet, "/users/"+bobID, bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, body = app.updateUser(bobID, bobToken, fiber.Map{"name": "Robert"})
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Robert", body["name"])
+
+	// but not Alice's
+	for _, method := range []string{fiber.MethodGet, fiber.MethodPut, fiber.MethodDelete} {
+		status, body = app.do(method, "/users/"+aliceID, bobToken, fiber.Map{"name": "Mallory"})
+		assert.Equal(t, fiber.StatusForbidden, status, method)
+		assert.Equal(t, "not_owner", body["code"], method)
+	}
+	status, body = app.do(fiber.MethodGet, "/users/"+aliceID, aliceToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Alice", body["name"])
+
+	// Admins act on anybody
+	status, _ = app.updateUser(aliceID, adminToken, fiber.Map{"name": "Alicia"})
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID, adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, _ = app.do(fiber.MethodDelete, "/users/"+bobID, bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func jsonDecode(resp *nethttp.Response, out interface{}) error {
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
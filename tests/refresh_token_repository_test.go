@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRefreshTokenRepository(t *testing.T, repo ports.RefreshTokenRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now()
+
+	token := &domain.RefreshToken{
+		UserID:    primitive.NewObjectID(),
+		FamilyID:  "family-1",
+		TokenHash: "hash-1",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+	require.NoError(t, repo.CreateRefreshToken(ctx, token))
+	require.NoError(t, repo.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    token.UserID,
+		FamilyID:  "family-1",
+		TokenHash: "hash-2",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}))
+
+	stored, err := repo.GetRefreshTokenByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, token.UserID, stored.UserID)
+	assert.Nil(t, stored.UsedAt)
+
+	_, err = repo.GetRefreshTokenByHash(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	// Only one of several concurrent rotations may win
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := repo.MarkRefreshTokenUsed(ctx, stored.ID.Hex(), time.Now()); err == nil && ok {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), wins.Load())
+
+	require.NoError(t, repo.RevokeRefreshTokenFamily(ctx, "family-1", time.Now()))
+	for _, hash := range []string{"hash-1", "hash-2"} {
+		revoked, err := repo.GetRefreshTokenByHash(ctx, hash)
+		require.NoError(t, err)
+		assert.NotNil(t, revoked.RevokedAt)
+	}
+
+	// Revoking a user reaches every family but leaves other users alone
+	other := &domain.RefreshToken{UserID: primitive.NewObjectID(), FamilyID: "family-2", TokenHash: "hash-3", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repo.CreateRefreshToken(ctx, other))
+	require.NoError(t, repo.CreateRefreshToken(ctx, &domain.RefreshToken{UserID: token.UserID, FamilyID: "family-3", TokenHash: "hash-4", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+	require.NoError(t, repo.RevokeUserRefreshTokens(ctx, token.UserID.Hex(), time.Now()))
+	revoked, err := repo.GetRefreshTokenByHash(ctx, "hash-4")
+	require.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	untouched, err := repo.GetRefreshTokenByHash(ctx, "hash-3")
+	require.NoError(t, err)
+	assert.Nil(t, untouched.RevokedAt)
+}
+
+func TestMemoryRefreshTokenRepository(t *testing.T) {
+	testRefreshTokenRepository(t, memory_repository.NewRefreshTokenRepository())
+}
+
+func TestGormRefreshTokenRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "tokens.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	testRefreshTokenRepository(t, gorm_repository.NewRefreshTokenRepository(db, 5*time.Second))
+}
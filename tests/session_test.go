@@ -0,0 +1,262 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/infrastructure/middleware"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loginFrom logs in with the given user agent and returns the access and refresh token.
+func (a *testApp) loginFrom(email string, userAgent string) (string, string) {
+	req := httptest.NewRequest(fiber.MethodPost, "/login", strings.NewReader(`{"email":"`+email+`","password":"Secret123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := a.app.Test(req, -1)
+	require.NoError(a.t, err)
+	defer resp.Body.Close()
+	require.Equal(a.t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(a.t, json.NewDecoder(resp.Body).Decode(&body))
+	return body["token"].(string), body["refresh_token"].(string)
+}
+
+func (a *testApp) sessions(userID string, token string) []map[string]interface{} {
+	status, body := a.do(fiber.MethodGet, "/users/"+userID+"/sessions", token, nil)
+	require.Equal(a.t, fiber.StatusOK, status)
+
+	var sessions []map[string]interface{}
+	for _, session := range body["sessions"].([]interface{}) {
+		sessions = append(sessions, session.(map[string]interface{}))
+	}
+	return sessions
+}
+
+func TestSessions_ListMarksTheCurrentOne(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	laptopToken, _ := app.loginFrom("alice@example.com", "laptop")
+	phoneToken, phoneRefresh := app.loginFrom("alice@example.com", "phone")
+	aliceID, _ := app.login("alice@example.com")
+
+	sessions := app.sessions(aliceID, laptopToken)
+	require.Len(t, sessions, 3)
+	current := map[string]bool{}
+	for _, session := range sessions {
+		current[session["user_agent"].(string)] = session["current"].(bool)
+		assert.NotEmpty(t, session["id"])
+		assert.NotEmpty(t, session["token_id"])
+	}
+	assert.Equal(t, map[string]bool{"laptop": true, "phone": false, "": false}, current)
+
+	// Refreshing keeps the session and moves it to the top
+	status, body := app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": phoneRefresh})
+	require.Equal(t, fiber.StatusOK, status)
+	sessions = app.sessions(aliceID, body["token"].(string))
+	require.Len(t, sessions, 3)
+	assert.Equal(t, "phone", sessions[0]["user_agent"])
+	assert.True(t, sessions[0]["current"].(bool))
+
+	// The phone's older access token belongs to the same session
+	sessions = app.sessions(aliceID, phoneToken)
+	assert.True(t, sessions[0]["current"].(bool))
+}
+
+func TestSessions_RevokeEndsTheSession(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	laptopToken, _ := app.loginFrom("alice@example.com", "laptop")
+	phoneToken, phoneRefresh := app.loginFrom("alice@example.com", "phone")
+	aliceID, _ := app.login("alice@example.com")
+
+	var phoneID string
+	for _, session := range app.sessions(aliceID, laptopToken) {
+		if session["user_agent"] == "phone" {
+			phoneID = session["id"].(string)
+		}
+	}
+	require.NotEmpty(t, phoneID)
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+aliceID+"/sessions/"+phoneID, laptopToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, body := app.do(fiber.MethodGet, "/users/"+aliceID, phoneToken, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "token_revoked", body["code"])
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": phoneRefresh})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Len(t, app.sessions(aliceID, laptopToken), 2)
+
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/sessions/"+phoneID, laptopToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
+
+func TestSessions_RevokeOthersKeepsTheCaller(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	laptopToken, laptopRefresh := app.loginFrom("alice@example.com", "laptop")
+	phoneToken, phoneRefresh := app.loginFrom("alice@example.com", "phone")
+	aliceID, _ := app.login("alice@example.com")
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+aliceID+"/sessions", laptopToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, phoneToken, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": phoneRefresh})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	sessions := app.sessions(aliceID, laptopToken)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "laptop", sessions[0]["user_agent"])
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": laptopRefresh})
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestSessions_OnlyOwnersAndAdmins(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Alice", "alice@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	aliceToken, _ := app.loginFrom("alice@example.com", "laptop")
+	aliceID, _ := app.login("alice@example.com")
+	_, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodGet, "/users/"+aliceID+"/sessions", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/sessions", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	sessions := app.sessions(aliceID, adminToken)
+	require.Len(t, sessions, 2)
+
+	// A session id does not reach across users
+	bobID, _ := app.login("bob@example.com")
+	status, _ = app.do(fiber.MethodDelete, "/users/"+bobID+"/sessions/"+sessions[0]["id"].(string), bobToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+
+	// An admin has no session of their own among the user's, all of them end
+	status, _ = app.do(fiber.MethodDelete, "/users/"+aliceID+"/sessions", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, aliceToken, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Empty(t, app.sessions(aliceID, adminToken))
+}
+
+func TestTokenAuthenticator_ChecksTheSessionStore(t *testing.T) {
+	ctx := context.Background()
+	sessions := memory_repository.NewSessionRepository()
+	now := time.Now()
+	require.NoError(t, sessions.CreateSession(ctx, &domain.Session{ID: "laptop", UserID: primitive.NewObjectID(), CreatedAt: now, LastSeenAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}))
+
+	claims := newTestAccessClaims()
+	claims.SessionID = "laptop"
+	signed, err := testKeys.Sign(claims)
+	require.NoError(t, err)
+	authorization := "Bearer " + signed
+	authenticate := func() error {
+		// A fresh revocation list each time, like a restarted or another instance
+		tokens := middleware.NewTokenAuthenticator(testKeys, testTokenValidation, memory_repository.NewTokenRevocationRepository(), sessions)
+		_, err := tokens.Authenticate(ctx, authorization)
+		return err
+	}
+
+	require.NoError(t, authenticate())
+	stored, err := sessions.GetSession(ctx, "laptop")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), stored.LastSeenAt, time.Minute)
+
+	require.NoError(t, sessions.RevokeSession(ctx, "laptop", time.Now()))
+	assert.ErrorIs(t, authenticate(), domain.ErrTokenRevoked)
+
+	claims.SessionID = "unknown"
+	signed, err = testKeys.Sign(claims)
+	require.NoError(t, err)
+	authorization = "Bearer " + signed
+	assert.ErrorIs(t, authenticate(), domain.ErrTokenRevoked)
+}
+
+func testSessionRepository(t *testing.T, repo ports.SessionRepositoryInterface) {
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	now := time.Now().Truncate(time.Millisecond)
+
+	_, err := repo.GetSession(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	assert.ErrorIs(t, repo.TouchSession(ctx, "missing", "jti", now, now), domain.ErrSessionNotFound)
+
+	for i, id := range []string{"session-1", "session-2", "session-3"} {
+		require.NoError(t, repo.CreateSession(ctx, &domain.Session{
+			ID:         id,
+			UserID:     userID,
+			UserAgent:  "agent",
+			IP:         "10.0.0.1",
+			TokenID:    "jti-" + id,
+			LastSeenAt: now.Add(time.Duration(i) * time.Second),
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(time.Hour),
+		}))
+	}
+	require.NoError(t, repo.CreateSession(ctx, &domain.Session{ID: "expired", UserID: userID, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(-time.Second)}))
+	require.NoError(t, repo.CreateSession(ctx, &domain.Session{ID: "other", UserID: primitive.NewObjectID(), CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}))
+
+	require.NoError(t, repo.TouchSession(ctx, "session-1", "jti-new", now.Add(time.Minute), now.Add(2*time.Hour)))
+	stored, err := repo.GetSession(ctx, "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, userID, stored.UserID)
+	assert.Equal(t, "jti-new", stored.TokenID)
+	assert.Equal(t, "10.0.0.1", stored.IP)
+	assert.True(t, stored.LastSeenAt.Equal(now.Add(time.Minute)))
+
+	// LastSeenAt only ever moves forward
+	require.NoError(t, repo.MarkSessionSeen(ctx, "session-1", now.Add(2*time.Minute)))
+	require.NoError(t, repo.MarkSessionSeen(ctx, "session-1", now))
+	stored, err = repo.GetSession(ctx, "session-1")
+	require.NoError(t, err)
+	assert.True(t, stored.LastSeenAt.Equal(now.Add(2*time.Minute)))
+
+	sessions, err := repo.ListUserSessions(ctx, userID.Hex(), now)
+	require.NoError(t, err)
+	var ids []string
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	assert.Equal(t, []string{"session-1", "session-3", "session-2"}, ids)
+
+	require.NoError(t, repo.RevokeSession(ctx, "session-3", now))
+	revoked, err := repo.RevokeUserSessions(ctx, userID.Hex(), "session-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session-2"}, revoked)
+	sessions, err = repo.ListUserSessions(ctx, userID.Hex(), now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "session-1", sessions[0].ID)
+
+	stored, err = repo.GetSession(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, stored.RevokedAt)
+}
+
+func TestMemorySessionRepository(t *testing.T) {
+	testSessionRepository(t, memory_repository.NewSessionRepository())
+}
+
+func TestGormSessionRepository(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "sessions.db"))
+	require.NoError(t, err)
+	testSessionRepository(t, gorm_repository.NewSessionRepository(db, time.Second))
+}
@@ -0,0 +1,183 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"testing"
+	"time"
+)
+
+// staleToken signs a token for the user as if it had been issued a while before the test.
+func (a *testApp) staleToken(userID string) string {
+	claims := newTestAccessClaims()
+	claims.Subject = userID
+	claims.Roles = []string{domain.RoleUser}
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+	claims.NotBefore = claims.IssuedAt
+	signed, err := testKeys.Sign(claims)
+	require.NoError(a.t, err)
+	return signed
+}
+
+func (a *testApp) loginWithRefresh(email string, password string) string {
+	status, body := a.do(fiber.MethodPost, "/login", "", fiber.Map{"email": email, "password": password})
+	require.Equal(a.t, fiber.StatusOK, status)
+	return body["refresh_token"].(string)
+}
+
+func TestTokenRevocationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewTokenRevocationRepository()
+	now := time.Now()
+
+	revoked, err := repo.IsAccessTokenRevoked(ctx, "jti-1", "", "user-1", now)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, repo.RevokeAccessToken(ctx, "jti-1", now.Add(time.Minute)))
+	revoked, err = repo.IsAccessTokenRevoked(ctx, "jti-1", "", "user-1", now)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, repo.RevokeUserAccessTokens(ctx, "user-2", now, now.Add(time.Minute)))
+	// An earlier cut-off does not bring older tokens back
+	require.NoError(t, repo.RevokeUserAccessTokens(ctx, "user-2", now.Add(-time.Hour), now.Add(time.Minute)))
+	revoked, err = repo.IsAccessTokenRevoked(ctx, "jti-2", "", "user-2", now.Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = repo.IsAccessTokenRevoked(ctx, "jti-3", "", "user-2", now)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenRevocationRepository_ForgetsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	repo := memory_repository.NewTokenRevocationRepository()
+	now := time.Now()
+
+	require.NoError(t, repo.RevokeAccessToken(ctx, "jti-1", now.Add(50*time.Millisecond)))
+	require.NoError(t, repo.RevokeUserAccessTokens(ctx, "user-1", now, now.Add(50*time.Millisecond)))
+	time.Sleep(100 * time.Millisecond)
+
+	revoked, err := repo.IsAccessTokenRevoked(ctx, "jti-1", "", "user-1", now.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Nor do they linger until the next write sweeps them out
+	require.NoError(t, repo.RevokeAccessToken(ctx, "jti-2", now.Add(time.Minute)))
+	revoked, err = repo.IsAccessTokenRevoked(ctx, "jti-1", "", "user-1", now.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenRevocation_PasswordChangeRevokesOldTokens(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, _ := app.login("alice@example.com")
+	refreshToken := app.loginWithRefresh("alice@example.com", "Secret123!")
+	stale := app.staleToken(aliceID)
+
+	status, _ := app.do(fiber.MethodGet, "/users/"+aliceID, stale, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, _ = app.do(fiber.MethodPut, "/users/"+aliceID+"/password", stale, fiber.Map{"current_password": "Secret123!", "new_password": "Changed123!"})
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, body := app.do(fiber.MethodGet, "/users/"+aliceID, stale, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "token_revoked", body["code"])
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": refreshToken})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// Logging in with the new password right away still works
+	status, body = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Changed123!"})
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, body["token"].(string), nil)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestTokenRevocation_PasswordChangeKeepsTheCurrentSession(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	aliceID, _ := app.login("alice@example.com")
+	laptopToken, laptopRefresh := app.loginFrom("alice@example.com", "laptop")
+	phoneToken, phoneRefresh := app.loginFrom("alice@example.com", "phone")
+
+	status, _ := app.do(fiber.MethodPut, "/users/"+aliceID+"/password", laptopToken, fiber.Map{"current_password": "Secret123!", "new_password": "Changed123!"})
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, body := app.do(fiber.MethodGet, "/users/"+aliceID, phoneToken, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "token_revoked", body["code"])
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": phoneRefresh})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, _ = app.do(fiber.MethodGet, "/users/"+aliceID, laptopToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": laptopRefresh})
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestTokenRevocation_DeletedUserTokensStopWorking(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	bobID, _ := app.login("bob@example.com")
+	stale := app.staleToken(bobID)
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+bobID, adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, body := app.do(fiber.MethodPost, "/verify-email/resend", stale, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "token_revoked", body["code"])
+}
+
+func TestTokenRevocation_AdminForceLogout(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	adminID, adminToken := app.login("root@example.com")
+	bobID, _ := app.login("bob@example.com")
+	refreshToken := app.loginWithRefresh("bob@example.com", "Secret123!")
+	stale := app.staleToken(bobID)
+
+	status, _ := app.do(fiber.MethodPost, "/users/"+adminID+"/logout", stale, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, _ = app.do(fiber.MethodPost, "/users/"+bobID+"/logout", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, _ = app.do(fiber.MethodGet, "/users/"+bobID, stale, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": refreshToken})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = app.do(fiber.MethodGet, "/users/"+adminID, adminToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestTokenRevocation_LogoutRevokesTheAccessToken(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Alice", "alice@example.com")
+	status, body := app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "alice@example.com", "password": "Secret123!"})
+	require.Equal(t, fiber.StatusOK, status)
+	accessToken, refreshToken := body["token"].(string), body["refresh_token"].(string)
+	otherRefreshToken := app.loginWithRefresh("alice@example.com", "Secret123!")
+
+	status, _ = app.do(fiber.MethodPost, "/logout", accessToken, fiber.Map{"refresh_token": refreshToken})
+	require.Equal(t, fiber.StatusOK, status)
+
+	status, body = app.do(fiber.MethodPost, "/verify-email/resend", accessToken, nil)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "token_revoked", body["code"])
+
+	// Only that session ends
+	status, _ = app.do(fiber.MethodPost, "/token/refresh", "", fiber.Map{"refresh_token": otherRefreshToken})
+	assert.Equal(t, fiber.StatusOK, status)
+}
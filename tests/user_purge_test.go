@@ -0,0 +1,160 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/gorm_repository"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"golang-rest/internal/infrastructure/audit"
+	"golang-rest/internal/infrastructure/background"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type purgeRepositories struct {
+	users         ports.UserRepositoryInterface
+	identities    ports.IdentityRepositoryInterface
+	mfa           ports.MFARepositoryInterface
+	sessions      ports.SessionRepositoryInterface
+	refreshTokens ports.RefreshTokenRepositoryInterface
+	resetTokens   ports.PasswordResetTokenRepositoryInterface
+	loginAttempts ports.LoginAttemptRepositoryInterface
+}
+
+// seedUserData gives the user one of everything the purge has to remove.
+func seedUserData(t *testing.T, repos purgeRepositories, user *domain.User) {
+	ctx := context.Background()
+	now := time.Now()
+	id := user.ID.Hex()
+	require.NoError(t, repos.identities.CreateIdentity(ctx, &domain.Identity{UserID: user.ID, Provider: "google", Subject: id, Email: user.Email, CreatedAt: now}))
+	require.NoError(t, repos.mfa.SaveMFA(ctx, &domain.MFA{UserID: user.ID, Secret: "secret", RecoveryCodes: []string{"code"}, CreatedAt: now}))
+	require.NoError(t, repos.sessions.CreateSession(ctx, &domain.Session{ID: "session-" + id, UserID: user.ID, LastSeenAt: now, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repos.refreshTokens.CreateRefreshToken(ctx, &domain.RefreshToken{UserID: user.ID, FamilyID: "session-" + id, TokenHash: "refresh-" + id, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repos.resetTokens.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{UserID: user.ID, TokenHash: "reset-" + id, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	_, err := repos.loginAttempts.RecordLoginFailure(ctx, "account:"+user.Email, now, time.Hour)
+	require.NoError(t, err)
+}
+
+func assertUserData(t *testing.T, repos purgeRepositories, user *domain.User, kept bool) {
+	ctx := context.Background()
+	id := user.ID.Hex()
+	_, err := repos.identities.GetIdentity(ctx, "google", id)
+	assert.Equal(t, kept, err == nil, "identity")
+	_, err = repos.mfa.GetMFA(ctx, id)
+	assert.Equal(t, kept, err == nil, "mfa")
+	_, err = repos.sessions.GetSession(ctx, "session-"+id)
+	assert.Equal(t, kept, err == nil, "session")
+	_, err = repos.refreshTokens.GetRefreshTokenByHash(ctx, "refresh-"+id)
+	assert.Equal(t, kept, err == nil, "refresh token")
+	_, err = repos.resetTokens.GetPasswordResetTokenByHash(ctx, "reset-"+id)
+	assert.Equal(t, kept, err == nil, "reset token")
+	attempts, err := repos.loginAttempts.GetLoginAttempts(ctx, "account:"+user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, kept, attempts.Failures > 0, "login attempts")
+}
+
+func testUserPurge(t *testing.T, repos purgeRepositories) {
+	ctx := context.Background()
+	purgeService := services.NewUserPurgeService(repos.users, repos.identities, repos.mfa, repos.sessions, repos.refreshTokens, repos.resetTokens, repos.loginAttempts)
+	bob := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123"}
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "pwd123"}
+	for _, user := range []*domain.User{bob, alice} {
+		require.NoError(t, repos.users.CreateUser(ctx, user))
+		seedUserData(t, repos, user)
+	}
+	require.NoError(t, repos.users.DeleteUserByID(ctx, bob.ID.Hex()))
+
+	assert.Zero(t, background.PurgeDeletedUsers(ctx, purgeService, time.Now().Add(-time.Hour)))
+	assertUserData(t, repos, bob, true)
+
+	assert.Equal(t, 1, background.PurgeDeletedUsers(ctx, purgeService, time.Now().Add(time.Second)))
+	_, err := repos.users.RestoreUserByID(ctx, bob.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assertUserData(t, repos, bob, false)
+	assertUserData(t, repos, alice, true)
+
+	// A new account on the purged email keeps its own failed logins
+	carol := &domain.User{Name: "Carol", Email: "carol@example.com", Password: "pwd123"}
+	require.NoError(t, repos.users.CreateUser(ctx, carol))
+	require.NoError(t, repos.users.DeleteUserByID(ctx, carol.ID.Hex()))
+	newCarol := &domain.User{Name: "New Carol", Email: "carol@example.com", Password: "pwd123"}
+	require.NoError(t, repos.users.CreateUser(ctx, newCarol))
+	seedUserData(t, repos, newCarol)
+	require.NoError(t, purgeService.PurgeUser(ctx, *carol, time.Now().Add(time.Second)))
+	assertUserData(t, repos, newCarol, true)
+	assert.ErrorIs(t, purgeService.PurgeUser(ctx, *carol, time.Now().Add(time.Second)), domain.ErrUserNotFound)
+}
+
+func newMemoryPurgeRepositories() purgeRepositories {
+	return purgeRepositories{
+		users:         memory_repository.NewUserRepository(),
+		identities:    memory_repository.NewIdentityRepository(),
+		mfa:           memory_repository.NewMFARepository(),
+		sessions:      memory_repository.NewSessionRepository(),
+		refreshTokens: memory_repository.NewRefreshTokenRepository(),
+		resetTokens:   memory_repository.NewPasswordResetTokenRepository(),
+		loginAttempts: memory_repository.NewLoginAttemptRepository(),
+	}
+}
+
+func TestMemoryUserPurge(t *testing.T) {
+	testUserPurge(t, newMemoryPurgeRepositories())
+}
+
+func TestUserPurge_RecordsAnAuditEventPerUser(t *testing.T) {
+	ctx := context.Background()
+	repos := newMemoryPurgeRepositories()
+	auditRepository := memory_repository.NewAuditRepository()
+	purgeService := audit.NewUserPurgeService(
+		services.NewUserPurgeService(repos.users, repos.identities, repos.mfa, repos.sessions, repos.refreshTokens, repos.resetTokens, repos.loginAttempts),
+		audit.NewRecorder(auditRepository),
+	)
+	var users []*domain.User
+	for _, email := range []string{"bob@example.com", "alice@example.com"} {
+		user := &domain.User{Name: "User", Email: email, Password: "pwd123"}
+		require.NoError(t, repos.users.CreateUser(ctx, user))
+		require.NoError(t, repos.users.DeleteUserByID(ctx, user.ID.Hex()))
+		users = append(users, user)
+	}
+
+	assert.Equal(t, 2, background.PurgeDeletedUsers(ctx, purgeService, time.Now().Add(time.Second)))
+	for _, user := range users {
+		page, err := auditRepository.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10, Action: domain.AuditUserPurge, TargetID: user.ID.Hex()})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		event := page.Events[0]
+		assert.Equal(t, domain.AuditOutcomeSuccess, event.Outcome)
+		assert.Equal(t, domain.AuditActorSystem, event.ActorType)
+		assert.Empty(t, event.ActorID)
+		assert.Equal(t, user.Email, event.TargetEmail)
+	}
+
+	// A user restored or purged by another instance meanwhile is no failure worth recording
+	assert.ErrorIs(t, purgeService.PurgeUser(ctx, *users[0], time.Now()), domain.ErrUserNotFound)
+	page, err := auditRepository.ListAuditEvents(ctx, domain.AuditQuery{Limit: 10, Action: domain.AuditUserPurge})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+}
+
+func TestGormUserPurge(t *testing.T) {
+	db, err := gorm_repository.Open(filepath.Join(t.TempDir(), "purge.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	testUserPurge(t, purgeRepositories{
+		users:         gorm_repository.NewUserRepository(db, 5*time.Second),
+		identities:    gorm_repository.NewIdentityRepository(db, 5*time.Second),
+		mfa:           gorm_repository.NewMFARepository(db, 5*time.Second),
+		sessions:      gorm_repository.NewSessionRepository(db, 5*time.Second),
+		refreshTokens: gorm_repository.NewRefreshTokenRepository(db, 5*time.Second),
+		resetTokens:   gorm_repository.NewPasswordResetTokenRepository(db, 5*time.Second),
+		loginAttempts: gorm_repository.NewLoginAttemptRepository(db, 5*time.Second),
+	})
+}
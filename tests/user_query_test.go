@@ -0,0 +1,76 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"testing"
+	"time"
+)
+
+// testUserQueries runs the same paging, sorting and filtering expectations against any repository.
+func testUserQueries(t *testing.T, repo ports.UserRepositoryInterface) {
+	ctx := context.Background()
+	for _, user := range []domain.User{
+		{Name: "Carol Smith", Email: "carol@example.com", Password: "pw"},
+		{Name: "alice smith", Email: "alice@example.com", Password: "pw"},
+		{Name: "Bob Jones", Email: "bob@test.org", Password: "pw"},
+		{Name: "Dave 100%_", Email: "dave@example.com", Password: "pw"},
+	} {
+		require.NoError(t, repo.CreateUser(ctx, &user))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	emails := func(page *domain.UserPage) []string {
+		var result []string
+		for _, user := range page.Users {
+			assert.Empty(t, user.Password)
+			result = append(result, user.Email)
+		}
+		return result
+	}
+
+	page, err := repo.GetAllUsers(ctx, domain.UserQuery{Limit: 2, SortBy: domain.SortByEmail})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, []string{"alice@example.com", "bob@test.org"}, emails(page))
+	assert.True(t, page.HasNext())
+
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 2, Offset: 2, SortBy: domain.SortByEmail})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol@example.com", "dave@example.com"}, emails(page))
+	assert.False(t, page.HasNext())
+
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByCreatedAt, SortDesc: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave@example.com", "bob@test.org", "alice@example.com", "carol@example.com"}, emails(page))
+
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByName, NameContains: "SMITH"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.ElementsMatch(t, []string{"alice@example.com", "carol@example.com"}, emails(page))
+
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByEmail, EmailPrefix: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob@test.org"}, emails(page))
+
+	// LIKE wildcards in the input are matched literally
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByEmail, NameContains: "0%_"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave@example.com"}, emails(page))
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByEmail, EmailPrefix: "%"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+
+	all, err := repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByCreatedAt})
+	require.NoError(t, err)
+	cutoff := all.Users[2].CreatedAt
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByCreatedAt, CreatedAfter: &cutoff})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob@test.org", "dave@example.com"}, emails(page))
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{Limit: 10, SortBy: domain.SortByCreatedAt, CreatedBefore: &cutoff})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol@example.com", "alice@example.com"}, emails(page))
+}
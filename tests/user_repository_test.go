@@ -1,14 +1,16 @@
 package repository_test
 
 import (
-	"errors"
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang-rest/internal/core/domain"
 	"golang-rest/internal/core/ports"
+	"slices"
+	"sort"
 	"testing"
+	"time"
 )
 
 type MockUserRepository struct {
@@ -20,42 +22,47 @@ func NewMockUserRepo() ports.UserRepositoryInterface {
 	return &MockUserRepository{Users: make(map[string]domain.User)}
 }
 
-func (m *MockUserRepository) CreateUser(user *domain.User) error {
+func (m *MockUserRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	args := m.Called(user)
 	m.Users[user.Email] = *user
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetAllUsers() ([]domain.User, error) {
-	args := m.Called()
+func (m *MockUserRepository) GetAllUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	args := m.Called(query)
 	var userList []domain.User
 	for _, u := range m.Users {
 		u.Password = ""
 		userList = append(userList, u)
 	}
-	return userList, args.Error(1)
+	sort.Slice(userList, func(i, j int) bool { return userList[i].Email < userList[j].Email })
+	return &domain.UserPage{Users: userList, Total: int64(len(userList)), Limit: query.Limit}, args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserByEmail(email string) (*domain.User, error) {
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	args := m.Called(email)
 	user, ok := m.Users[email]
 	if !ok {
-		return nil, errors.New("not found")
+		return nil, domain.ErrUserNotFound
 	}
 	user.Password = ""
 	return &user, args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserLoginByEmail(email string) (*domain.User, error) {
+func (m *MockUserRepository) GetUserLoginByEmail(ctx context.Context, email string) (*domain.User, error) {
 	args := m.Called(email)
 	user, ok := m.Users[email]
 	if !ok {
-		return nil, errors.New("not found")
+		return nil, domain.ErrUserNotFound
 	}
 	return &user, args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserByID(id string) (*domain.User, error) {
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
 	args := m.Called(id)
 	for _, u := range m.Users {
 		if u.ID.Hex() == id {
@@ -63,27 +70,34 @@ func (m *MockUserRepository) GetUserByID(id string) (*domain.User, error) {
 			return &u, args.Error(1)
 		}
 	}
-	return nil, errors.New("not found")
+	return nil, domain.ErrUserNotFound
 }
 
-func (m *MockUserRepository) UpdateUserByID(id string, updates bson.M) (*domain.User, error) {
-	args := m.Called(id, updates)
+func (m *MockUserRepository) UpdateUserByID(ctx context.Context, id string, version int64, update domain.UserUpdate) (*domain.User, error) {
+	args := m.Called(id, update)
 	for k, u := range m.Users {
 		if u.ID.Hex() == id {
-			if name, ok := updates["name"].(string); ok {
-				u.Name = name
+			if version != domain.AnyVersion && u.Version != version {
+				return nil, domain.ErrVersionMismatch
+			}
+			u.Version++
+			if update.Name != nil {
+				u.Name = *update.Name
 			}
-			if email, ok := updates["email"].(string); ok {
-				u.Email = email
+			if update.Email != nil {
+				u.Email = *update.Email
+			}
+			if update.EmailVerified != nil {
+				u.EmailVerified = *update.EmailVerified
 			}
 			m.Users[k] = u
 			return &u, args.Error(1)
 		}
 	}
-	return nil, errors.New("not found")
+	return nil, domain.ErrUserNotFound
 }
 
-func (m *MockUserRepository) DeleteUserByID(id string) error {
+func (m *MockUserRepository) DeleteUserByID(ctx context.Context, id string) error {
 	args := m.Called(id)
 	for k, u := range m.Users {
 		if u.ID.Hex() == id {
@@ -91,7 +105,56 @@ func (m *MockUserRepository) DeleteUserByID(id string) error {
 			return args.Error(0)
 		}
 	}
-	return errors.New("not found")
+	return domain.ErrUserNotFound
+}
+
+func (m *MockUserRepository) RestoreUserByID(ctx context.Context, id string) (*domain.User, error) {
+	args := m.Called(id)
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) GetDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]domain.User, error) {
+	args := m.Called(deletedBefore)
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) PurgeUserByID(ctx context.Context, id string, deletedBefore time.Time) error {
+	args := m.Called(id, deletedBefore)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, id string, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkUserEmailVerified(ctx context.Context, id string, email string) error {
+	args := m.Called(id, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) AddUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	args := m.Called(id, role)
+	for k, u := range m.Users {
+		if u.ID.Hex() == id {
+			u.Roles = append(u.Roles, role)
+			m.Users[k] = u
+			return &u, args.Error(1)
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (m *MockUserRepository) RemoveUserRole(ctx context.Context, id string, role string) (*domain.User, error) {
+	args := m.Called(id, role)
+	for k, u := range m.Users {
+		if u.ID.Hex() == id {
+			u.Roles = slices.DeleteFunc(u.Roles, func(r string) bool { return r == role })
+			m.Users[k] = u
+			return &u, args.Error(1)
+		}
+	}
+	return nil, domain.ErrUserNotFound
 }
 
 func TestUserRepoMock_CreateAndFetchUser(t *testing.T) {
@@ -104,10 +167,10 @@ func TestUserRepoMock_CreateAndFetchUser(t *testing.T) {
 		Email:    "alice@example.com",
 		Password: "secret",
 	}
-	err := mockRepo.CreateUser(user)
+	err := mockRepo.CreateUser(context.Background(), user)
 	assert.NoError(t, err)
 
-	res, err := mockRepo.GetUserByEmail("alice@example.com")
+	res, err := mockRepo.GetUserByEmail(context.Background(), "alice@example.com")
 	assert.NoError(t, err)
 	assert.Equal(t, "Alice", res.Name)
 	assert.Empty(t, res.Password)
@@ -125,14 +188,13 @@ func TestUserRepoMock_UpdateAndDeleteUser(t *testing.T) {
 		Email:    "bob@example.com",
 		Password: "pwd123",
 	}
-	mockRepo.CreateUser(user)
+	mockRepo.CreateUser(context.Background(), user)
 
-	updates := bson.M{"name": "Robert"}
-	updated, err := mockRepo.UpdateUserByID(user.ID.Hex(), updates)
+	updated, err := mockRepo.UpdateUserByID(context.Background(), user.ID.Hex(), 0, domain.UserUpdate{Name: ptr("Robert")})
 	assert.NoError(t, err)
 	assert.Equal(t, "Robert", updated.Name)
 
-	err = mockRepo.DeleteUserByID(user.ID.Hex())
+	err = mockRepo.DeleteUserByID(context.Background(), user.ID.Hex())
 	assert.NoError(t, err)
 }
 
@@ -157,7 +219,7 @@ func TestUserRepoMock_Login(t *testing.T) {
 	mockRepo.On("GetUserLoginByEmail", testUser.Email).Return(&testUser, nil)
 
 	// Call the method under test
-	user, err := mockRepo.GetUserLoginByEmail(testUser.Email)
+	user, err := mockRepo.GetUserLoginByEmail(context.Background(), testUser.Email)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -194,13 +256,14 @@ func TestUserRepoMock_GetAllUsers(t *testing.T) {
 	mockRepo.Users[user2.Email] = user2
 
 	// Set up expectations
-	mockRepo.On("GetAllUsers").Return([]domain.User{user1, user2}, nil)
+	mockRepo.On("GetAllUsers", mock.Anything).Return(&domain.UserPage{Users: []domain.User{user1, user2}}, nil)
 
 	// Call the method under test
-	users, err := mockRepo.GetAllUsers()
+	page, err := mockRepo.GetAllUsers(context.Background(), domain.UserQuery{})
 
 	// Assertions
 	assert.NoError(t, err)
+	users := page.Users
 	assert.Len(t, users, 2)
 	assert.Equal(t, "Alice", users[0].Name)
 	assert.Equal(t, "Bob", users[1].Name)
@@ -210,3 +273,7 @@ func TestUserRepoMock_GetAllUsers(t *testing.T) {
 	// Assert that the expectations were met
 	mockRepo.AssertExpectations(t)
 }
+
+func ptr[T any](value T) *T {
+	return &value
+}
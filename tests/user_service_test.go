@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"testing"
+)
+
+func TestUserService_RegisterUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockUserRepository{Users: make(map[string]domain.User)}
+	mockRepo.On("GetUserByEmail", "alice@example.com").Return(nil, nil)
+	mockRepo.On("CreateUser", mock.Anything).Return(nil)
+	userService := services.NewUserService(mockRepo, nil, nil, services.UserConfig{})
+
+	user, err := userService.RegisterUser(ctx, ports.RegisterUserInput{
+		Name:     "Alice",
+		Email:    "alice@example.com",
+		Password: "secret",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", user.Name)
+	assert.Empty(t, user.Password)
+
+	// The second registration with the same email is rejected before reaching CreateUser
+	_, err = userService.RegisterUser(ctx, ports.RegisterUserInput{
+		Name:     "Alice Again",
+		Email:    "alice@example.com",
+		Password: "secret",
+	})
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+	mockRepo.AssertNumberOfCalls(t, "CreateUser", 1)
+}
+
+func TestUserService_RegisterUserMissingFields(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockUserRepository{Users: make(map[string]domain.User)}
+	userService := services.NewUserService(mockRepo, nil, nil, services.UserConfig{})
+
+	_, err := userService.RegisterUser(ctx, ports.RegisterUserInput{Email: "bob@example.com"})
+	assert.ErrorIs(t, err, domain.ErrMissingFields)
+}
+
+func TestUserService_UpdateUserByIDWhitelist(t *testing.T) {
+	ctx := context.Background()
+	testUser := domain.User{ID: primitive.NewObjectID(), Name: "Bob", Email: "bob@example.com"}
+	mockRepo := &MockUserRepository{Users: map[string]domain.User{testUser.Email: testUser}}
+	mockRepo.On("GetUserByID", testUser.ID.Hex()).Return(nil, nil)
+	mockRepo.On("UpdateUserByID", testUser.ID.Hex(), mock.Anything).Return(nil, nil)
+	userService := services.NewUserService(mockRepo, nil, nil, services.UserConfig{})
+
+	name := "Robert"
+	updated, err := userService.UpdateUserByID(ctx, testUser.ID.Hex(), ports.UpdateUserInput{Name: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Equal(t, "bob@example.com", updated.Email)
+}
+
+func TestUserService_GetAllUsersQueryValidation(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockUserRepository{Users: make(map[string]domain.User)}
+	mockRepo.On("GetAllUsers", mock.Anything).Return(nil, nil)
+	userService := services.NewUserService(mockRepo, nil, nil, services.UserConfig{})
+
+	_, err := userService.GetAllUsers(ctx, domain.UserQuery{SortBy: "password"})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+
+	_, err = userService.GetAllUsers(ctx, domain.UserQuery{Offset: -1})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+
+	page, err := userService.GetAllUsers(ctx, domain.UserQuery{Limit: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MaxUserPageLimit, page.Limit)
+	mockRepo.AssertCalled(t, "GetAllUsers", domain.UserQuery{Limit: domain.MaxUserPageLimit, SortBy: domain.SortByCreatedAt})
+}
+
+func TestUserService_Roles(t *testing.T) {
+	ctx := context.Background()
+	userService := services.NewUserService(memory_repository.NewUserRepository(), nil, nil, services.UserConfig{
+		AdminEmails: []string{"root@example.com"},
+	})
+
+	admin, err := userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Root", Email: "root@example.com", Password: "pw"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{domain.RoleUser, domain.RoleAdmin}, admin.Roles)
+
+	user, err := userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "pw"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleUser}, user.Roles)
+
+	_, err = userService.GrantRole(ctx, user.ID.Hex(), "superuser")
+	assert.ErrorIs(t, err, domain.ErrInvalidRole)
+
+	granted, err := userService.GrantRole(ctx, user.ID.Hex(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.True(t, domain.HasPermission(granted.Roles, domain.PermissionUsersDelete))
+
+	// Granting twice keeps a single entry
+	granted, err = userService.GrantRole(ctx, user.ID.Hex(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Len(t, granted.Roles, 2)
+
+	revoked, err := userService.RevokeRole(ctx, user.ID.Hex(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.False(t, domain.HasPermission(revoked.Roles, domain.PermissionUsersDelete))
+	assert.True(t, domain.CanAccessUser(user.ID.Hex(), revoked.Roles, user.ID.Hex(), domain.PermissionUsersDelete))
+	assert.False(t, domain.CanAccessUser(user.ID.Hex(), revoked.Roles, admin.ID.Hex(), domain.PermissionUsersRead))
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"testing"
+	"time"
+)
+
+func testUserSoftDelete(t *testing.T, repo ports.UserRepositoryInterface) {
+	ctx := context.Background()
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123", Roles: []string{domain.RoleAdmin}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	id := user.ID.Hex()
+
+	require.NoError(t, repo.DeleteUserByID(ctx, id))
+	assert.ErrorIs(t, repo.DeleteUserByID(ctx, id), domain.ErrUserNotFound)
+	assert.ErrorIs(t, repo.DeleteUserByID(ctx, primitive.NewObjectID().Hex()), domain.ErrUserNotFound)
+
+	_, err := repo.GetUserByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.GetUserLoginByEmail(ctx, "bob@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.UpdateUserByID(ctx, id, user.Version, domain.UserUpdate{Name: ptr("Robert")})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.ErrorIs(t, repo.UpdateUserPassword(ctx, id, "other"), domain.ErrUserNotFound)
+	_, err = repo.AddUserRole(ctx, id, domain.RoleUser)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	page, err := repo.GetAllUsers(ctx, domain.UserQuery{})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+
+	restored, err := repo.RestoreUserByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", restored.Name)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, []string{domain.RoleAdmin}, restored.Roles)
+	_, err = repo.RestoreUserByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	login, err := repo.GetUserLoginByEmail(ctx, "bob@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, login.ID)
+
+	// The email is free while the user is deleted, which then blocks the restore
+	require.NoError(t, repo.DeleteUserByID(ctx, id))
+	newBob := &domain.User{Name: "New Bob", Email: "bob@example.com", Password: "pwd123"}
+	require.NoError(t, repo.CreateUser(ctx, newBob))
+	_, err = repo.RestoreUserByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+
+	deleted, err := repo.GetDeletedUsers(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.ErrorIs(t, repo.PurgeUserByID(ctx, id, time.Now().Add(-time.Hour)), domain.ErrUserNotFound)
+	assert.ErrorIs(t, repo.PurgeUserByID(ctx, newBob.ID.Hex(), time.Now().Add(time.Second)), domain.ErrUserNotFound)
+
+	deleted, err = repo.GetDeletedUsers(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, user.ID, deleted[0].ID)
+	assert.Equal(t, "bob@example.com", deleted[0].Email)
+	assert.Empty(t, deleted[0].Password)
+	require.NoError(t, repo.PurgeUserByID(ctx, id, time.Now().Add(time.Second)))
+	assert.ErrorIs(t, repo.PurgeUserByID(ctx, id, time.Now().Add(time.Second)), domain.ErrUserNotFound)
+	_, err = repo.RestoreUserByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	page, err = repo.GetAllUsers(ctx, domain.UserQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "New Bob", page.Users[0].Name)
+}
+
+func TestMemoryUserRepository_SoftDelete(t *testing.T) {
+	testUserSoftDelete(t, memory_repository.NewUserRepository())
+}
+
+func TestGormUserRepository_SoftDelete(t *testing.T) {
+	testUserSoftDelete(t, newGormUserRepository(t))
+}
+
+func TestUsers_DeleteAndRestore(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+primitive.NewObjectID().Hex(), adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+
+	status, _ = app.do(fiber.MethodPost, "/users/"+bobID+"/restore", bobToken, nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, _ = app.do(fiber.MethodDelete, "/users/"+bobID, bobToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = app.do(fiber.MethodDelete, "/users/"+bobID, adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	status, _ = app.do(fiber.MethodGet, "/users/"+bobID, adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	status, _ = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "bob@example.com", "password": "Secret123!"})
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, body := app.do(fiber.MethodPost, "/users/"+bobID+"/restore", adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "bob@example.com", body["email"])
+	status, _ = app.do(fiber.MethodPost, "/users/"+bobID+"/restore", adminToken, nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+
+	_, bobToken = app.login("bob@example.com")
+	status, _ = app.do(fiber.MethodGet, "/users/"+bobID, bobToken, nil)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestUsers_RestoreAfterTheEmailWasTaken(t *testing.T) {
+	app := newTestApp(t, "root@example.com")
+	app.register("Root", "root@example.com")
+	app.register("Bob", "bob@example.com")
+	_, adminToken := app.login("root@example.com")
+	bobID, _ := app.login("bob@example.com")
+
+	status, _ := app.do(fiber.MethodDelete, "/users/"+bobID, adminToken, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	app.register("New Bob", "bob@example.com")
+
+	status, _ = app.do(fiber.MethodPost, "/users/"+bobID+"/restore", adminToken, nil)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
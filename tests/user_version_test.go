@@ -0,0 +1,146 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func testUserVersions(t *testing.T, repo ports.UserRepositoryInterface) {
+	ctx := context.Background()
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	id := user.ID.Hex()
+	assert.Equal(t, int64(1), user.Version)
+
+	updated, err := repo.UpdateUserByID(ctx, id, 1, domain.UserUpdate{Name: ptr("Robert")})
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = repo.UpdateUserByID(ctx, id, 1, domain.UserUpdate{Name: ptr("Bobby")})
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+	_, err = repo.UpdateUserByID(ctx, primitive.NewObjectID().Hex(), 1, domain.UserUpdate{Name: ptr("Bobby")})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// Every other write moves the version on as well
+	updated, err = repo.AddUserRole(ctx, id, domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated.Version)
+	require.NoError(t, repo.MarkUserEmailVerified(ctx, id, "bob@example.com"))
+	stored, err := repo.GetUserByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stored.Version)
+
+	// Writers racing on the same version: exactly one wins
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.UpdateUserByID(ctx, id, stored.Version, domain.UserUpdate{Name: ptr("Racer")}); err == nil {
+				wins.Add(1)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), wins.Load())
+
+	// AnyVersion writes over whatever is current
+	updated, err = repo.UpdateUserByID(ctx, id, domain.AnyVersion, domain.UserUpdate{Name: ptr("Bob")})
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", updated.Name)
+	assert.Equal(t, stored.Version+2, updated.Version)
+	_, err = repo.UpdateUserByID(ctx, primitive.NewObjectID().Hex(), domain.AnyVersion, domain.UserUpdate{Name: ptr("Bobby")})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+// racingUserRepository lets another writer in right after the service read the user.
+type racingUserRepository struct {
+	ports.UserRepositoryInterface
+	raced bool
+}
+
+func (r *racingUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	user, err := r.UserRepositoryInterface.GetUserByID(ctx, id)
+	if err == nil && !r.raced {
+		r.raced = true
+		_, err = r.UserRepositoryInterface.UpdateUserByID(ctx, id, domain.AnyVersion, domain.UserUpdate{Name: ptr("Racer")})
+	}
+	return user, err
+}
+
+func TestUserService_UpdateRacingAnotherWrite(t *testing.T) {
+	ctx := context.Background()
+	repo := &racingUserRepository{UserRepositoryInterface: memory_repository.NewUserRepository()}
+	userService := services.NewUserService(repo, nil, nil, services.UserConfig{})
+	user := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "pwd123"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	// The version the caller read is gone by the time of the write
+	_, err := userService.UpdateUserByID(ctx, user.ID.Hex(), ports.UpdateUserInput{Name: ptr("Robert"), Version: ptr(user.Version)})
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+
+	// Without a version, as for If-Match: *, the write goes through anyway
+	repo.raced = false
+	updated, err := userService.UpdateUserByID(ctx, user.ID.Hex(), ports.UpdateUserInput{Name: ptr("Robert")})
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Equal(t, user.Version+3, updated.Version)
+}
+
+func TestMemoryUserRepository_Versions(t *testing.T) {
+	testUserVersions(t, memory_repository.NewUserRepository())
+}
+
+func TestGormUserRepository_Versions(t *testing.T) {
+	testUserVersions(t, newGormUserRepository(t))
+}
+
+func TestUsers_UpdateRequiresMatchingETag(t *testing.T) {
+	app := newTestApp(t)
+	app.register("Bob", "bob@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+	path := "/users/" + bobID
+
+	status, header, body := app.doWithHeaders(fiber.MethodGet, path, bobToken, nil, nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, `"1"`, header.Get("ETag"))
+	assert.Equal(t, float64(1), body["version"])
+
+	status, body = app.do(fiber.MethodPut, path, bobToken, fiber.Map{"name": "Robert"})
+	assert.Equal(t, fiber.StatusPreconditionRequired, status)
+	assert.Equal(t, "if_match_required", body["code"])
+
+	for _, tag := range []string{`"2"`, `W/"1"`, `1`, `"one"`} {
+		status, _, body = app.doWithHeaders(fiber.MethodPut, path, bobToken, map[string]string{"If-Match": tag}, fiber.Map{"name": "Robert"})
+		assert.Equal(t, fiber.StatusPreconditionFailed, status, tag)
+		assert.Equal(t, "version_mismatch", body["code"], tag)
+	}
+
+	status, header, body = app.doWithHeaders(fiber.MethodPut, path, bobToken, map[string]string{"If-Match": `"1"`}, fiber.Map{"name": "Robert"})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Robert", body["name"])
+	assert.Equal(t, `"2"`, header.Get("ETag"))
+
+	// A second client still holding the first ETag loses instead of overwriting
+	status, _, _ = app.doWithHeaders(fiber.MethodPut, path, bobToken, map[string]string{"If-Match": `"1"`}, fiber.Map{"name": "Bobby"})
+	assert.Equal(t, fiber.StatusPreconditionFailed, status)
+
+	status, header, body = app.doWithHeaders(fiber.MethodPut, path, bobToken, map[string]string{"If-Match": "*"}, fiber.Map{"name": "Bobby"})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Bobby", body["name"])
+	assert.Equal(t, `"3"`, header.Get("ETag"))
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang-rest/internal/adapters/outbound/memory_repository"
+	"golang-rest/internal/core/domain"
+	"golang-rest/internal/core/ports"
+	"golang-rest/internal/core/services"
+	"strings"
+	"testing"
+)
+
+func fieldCodes(t *testing.T, err error) map[string]string {
+	var validationErr *domain.ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+
+	codes := map[string]string{}
+	for _, field := range validationErr.Fields {
+		codes[field.Field] = field.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	strict := domain.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+	cases := []struct {
+		password string
+		code     string
+	}{
+		{"Sh0rt!", domain.ValidationTooShort},
+		{"alllowercase1!", domain.ValidationWeakPassword},
+		{"ALLUPPERCASE1!", domain.ValidationWeakPassword},
+		{"NoDigitsHere!", domain.ValidationWeakPassword},
+		{"NoSymbols123", domain.ValidationWeakPassword},
+		{strings.Repeat("Aa1!", 19), domain.ValidationTooLong},
+		{"Correct-Horse-42", ""},
+	}
+	for _, c := range cases {
+		code, _ := strict.Check(c.password)
+		assert.Equal(t, c.code, code, c.password)
+	}
+
+	code, _ := domain.DefaultPasswordPolicy.Check("password1")
+	assert.Empty(t, code)
+}
+
+func TestUserService_RegisterValidation(t *testing.T) {
+	ctx := context.Background()
+	userService := services.NewUserService(memory_repository.NewUserRepository(), nil, nil, services.UserConfig{PasswordPolicy: domain.DefaultPasswordPolicy})
+
+	_, err := userService.RegisterUser(ctx, ports.RegisterUserInput{Name: " ", Email: "Bob <bob@example.com>", Password: "short"})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.ErrorIs(t, err, domain.ErrMissingFields)
+	assert.Equal(t, map[string]string{
+		"name":     domain.ValidationRequired,
+		"email":    domain.ValidationInvalidEmail,
+		"password": domain.ValidationTooShort,
+	}, fieldCodes(t, err))
+
+	_, err = userService.RegisterUser(ctx, ports.RegisterUserInput{Name: strings.Repeat("x", 101), Email: "bob@localhost", Password: "longenough"})
+	assert.NotErrorIs(t, err, domain.ErrMissingFields)
+	assert.Equal(t, map[string]string{
+		"name":     domain.ValidationTooLong,
+		"email":    domain.ValidationInvalidEmail,
+		"password": domain.ValidationWeakPassword,
+	}, fieldCodes(t, err))
+
+	user, err := userService.RegisterUser(ctx, ports.RegisterUserInput{Name: "Bob", Email: "bob@example.com", Password: "longenough1"})
+	require.NoError(t, err)
+
+	empty := ""
+	_, err = userService.UpdateUserByID(ctx, user.ID.Hex(), ports.UpdateUserInput{Email: &empty})
+	assert.Equal(t, map[string]string{"email": domain.ValidationRequired}, fieldCodes(t, err))
+}
+
+func TestValidationResponses(t *testing.T) {
+	app := newTestApp(t)
+
+	status, body := app.do(fiber.MethodPost, "/register", "", fiber.Map{"name": "Bob", "email": "not-an-email", "password": "weak"})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "validation_failed", body["code"])
+	assert.ElementsMatch(t, []interface{}{
+		map[string]interface{}{"field": "email", "code": "invalid_email", "message": "must be a valid email address"},
+		map[string]interface{}{"field": "password", "code": "too_short", "message": "must be at least 8 characters"},
+	}, body["fields"])
+
+	status, body = app.do(fiber.MethodPost, "/register", "", fiber.Map{"name": "Bob", "email": "bob@example.com", "password": "Secret123!", "role": "admin"})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, []interface{}{map[string]interface{}{"field": "role", "code": "unknown_field", "message": "is not allowed"}}, body["fields"])
+
+	status, body = app.do(fiber.MethodPost, "/login", "", fiber.Map{"email": "bob@example.com", "password": 123})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "invalid_type", body["fields"].([]interface{})[0].(map[string]interface{})["code"])
+
+	app.register("Bob", "bob@example.com")
+	bobID, bobToken := app.login("bob@example.com")
+
+	status, body = app.updateUser(bobID, bobToken, fiber.Map{"email": ""})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "required", body["fields"].([]interface{})[0].(map[string]interface{})["code"])
+
+	// The password is changed through its own endpoint only
+	status, body = app.do(fiber.MethodPut, "/users/"+bobID, bobToken, fiber.Map{"password": "Hijacked123!"})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "unknown_field", body["fields"].([]interface{})[0].(map[string]interface{})["code"])
+}